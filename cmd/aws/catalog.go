@@ -0,0 +1,125 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+// instanceTypeInfo describes an EC2 instance type, prices are Linux on-demand in us-east-1
+// and network bandwidth is the baseline, not the burst bandwidth
+type instanceTypeInfo struct {
+	vCPUs       int
+	memoryGiB   float64
+	hourlyPrice float64
+	networkGbps float64
+}
+
+// instanceCatalog is an offline catalog of the instance types considered for rightsizing
+var instanceCatalog = map[string]instanceTypeInfo{
+	"t3.nano":    {2, 0.5, 0.0052, 0.032},
+	"t3.micro":   {2, 1, 0.0104, 0.064},
+	"t3.small":   {2, 2, 0.0208, 0.128},
+	"t3.medium":  {2, 4, 0.0416, 0.256},
+	"t3.large":   {2, 8, 0.0832, 0.512},
+	"t3.xlarge":  {4, 16, 0.1664, 1.024},
+	"t3.2xlarge": {8, 32, 0.3328, 2.048},
+
+	"t3a.nano":    {2, 0.5, 0.0047, 0.032},
+	"t3a.micro":   {2, 1, 0.0094, 0.064},
+	"t3a.small":   {2, 2, 0.0188, 0.128},
+	"t3a.medium":  {2, 4, 0.0376, 0.256},
+	"t3a.large":   {2, 8, 0.0752, 0.512},
+	"t3a.xlarge":  {4, 16, 0.1504, 1.024},
+	"t3a.2xlarge": {8, 32, 0.3008, 2.048},
+
+	"t4g.nano":    {2, 0.5, 0.0042, 0.032},
+	"t4g.micro":   {2, 1, 0.0084, 0.064},
+	"t4g.small":   {2, 2, 0.0168, 0.128},
+	"t4g.medium":  {2, 4, 0.0336, 0.256},
+	"t4g.large":   {2, 8, 0.0672, 0.512},
+	"t4g.xlarge":  {4, 16, 0.1344, 1.024},
+	"t4g.2xlarge": {8, 32, 0.2688, 2.048},
+
+	"m5.large":    {2, 8, 0.096, 0.75},
+	"m5.xlarge":   {4, 16, 0.192, 1.25},
+	"m5.2xlarge":  {8, 32, 0.384, 2.5},
+	"m5.4xlarge":  {16, 64, 0.768, 5},
+	"m5.8xlarge":  {32, 128, 1.536, 10},
+	"m5.12xlarge": {48, 192, 2.304, 12},
+	"m5.16xlarge": {64, 256, 3.072, 20},
+	"m5.24xlarge": {96, 384, 4.608, 25},
+
+	"m5a.large":    {2, 8, 0.086, 0.75},
+	"m5a.xlarge":   {4, 16, 0.172, 1.25},
+	"m5a.2xlarge":  {8, 32, 0.344, 2.5},
+	"m5a.4xlarge":  {16, 64, 0.688, 5},
+	"m5a.8xlarge":  {32, 128, 1.376, 7.5},
+	"m5a.12xlarge": {48, 192, 2.064, 10},
+	"m5a.16xlarge": {64, 256, 2.752, 12},
+	"m5a.24xlarge": {96, 384, 4.128, 20},
+
+	"m6g.medium":   {1, 4, 0.0385, 0.5},
+	"m6g.large":    {2, 8, 0.077, 0.75},
+	"m6g.xlarge":   {4, 16, 0.154, 1.25},
+	"m6g.2xlarge":  {8, 32, 0.308, 2.5},
+	"m6g.4xlarge":  {16, 64, 0.616, 5},
+	"m6g.8xlarge":  {32, 128, 1.232, 12},
+	"m6g.12xlarge": {48, 192, 1.848, 20},
+	"m6g.16xlarge": {64, 256, 2.464, 25},
+
+	"c5.large":    {2, 4, 0.085, 0.75},
+	"c5.xlarge":   {4, 8, 0.17, 1.25},
+	"c5.2xlarge":  {8, 16, 0.34, 2.5},
+	"c5.4xlarge":  {16, 32, 0.68, 5},
+	"c5.9xlarge":  {36, 72, 1.53, 12},
+	"c5.12xlarge": {48, 96, 2.04, 12},
+	"c5.18xlarge": {72, 144, 3.06, 25},
+	"c5.24xlarge": {96, 192, 4.08, 25},
+
+	"c6g.medium":   {1, 2, 0.034, 0.5},
+	"c6g.large":    {2, 4, 0.068, 0.75},
+	"c6g.xlarge":   {4, 8, 0.136, 1.25},
+	"c6g.2xlarge":  {8, 16, 0.272, 2.5},
+	"c6g.4xlarge":  {16, 32, 0.544, 5},
+	"c6g.8xlarge":  {32, 64, 1.088, 12},
+	"c6g.12xlarge": {48, 96, 1.632, 20},
+	"c6g.16xlarge": {64, 128, 2.176, 25},
+
+	"r5.large":    {2, 16, 0.126, 0.75},
+	"r5.xlarge":   {4, 32, 0.252, 1.25},
+	"r5.2xlarge":  {8, 64, 0.504, 2.5},
+	"r5.4xlarge":  {16, 128, 1.008, 5},
+	"r5.8xlarge":  {32, 256, 2.016, 10},
+	"r5.12xlarge": {48, 384, 3.024, 12},
+	"r5.16xlarge": {64, 512, 4.032, 20},
+	"r5.24xlarge": {96, 768, 6.048, 25},
+
+	"r6g.medium":   {1, 8, 0.0504, 0.5},
+	"r6g.large":    {2, 16, 0.1008, 0.75},
+	"r6g.xlarge":   {4, 32, 0.2016, 1.25},
+	"r6g.2xlarge":  {8, 64, 0.4032, 2.5},
+	"r6g.4xlarge":  {16, 128, 0.8064, 5},
+	"r6g.8xlarge":  {32, 256, 1.6128, 12},
+	"r6g.12xlarge": {48, 384, 2.4192, 20},
+	"r6g.16xlarge": {64, 512, 3.2256, 25},
+}
+
+// gravitonFamilies maps instance families to their Graviton equivalents
+var gravitonFamilies = map[string]string{
+	"t3":  "t4g",
+	"t3a": "t4g",
+	"m5":  "m6g",
+	"m5a": "m6g",
+	"c5":  "c6g",
+	"r5":  "r6g",
+}
@@ -0,0 +1,227 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+// getMetricStatistics returns datapoints of the metric for the last given number of days
+func getMetricStatistics(cwSvc *cloudwatch.CloudWatch, namespace, metricName, statistic string, dimensions []*cloudwatch.Dimension, days int) ([]*cloudwatch.Datapoint, error) {
+	endTime := time.Now()
+	startTime := endTime.AddDate(0, 0, -days)
+	period := metricPeriod(days)
+
+	metricInput := &cloudwatch.GetMetricStatisticsInput{
+		MetricName: aws.String(metricName),
+		Namespace:  aws.String(namespace),
+		Statistics: aws.StringSlice([]string{statistic}),
+		Dimensions: dimensions,
+		StartTime:  &startTime,
+		EndTime:    &endTime,
+		Period:     &period,
+	}
+	metricOutput, err := cwSvc.GetMetricStatistics(metricInput)
+	if err != nil {
+		return nil, err
+	}
+	return metricOutput.Datapoints, nil
+}
+
+// metricPeriod returns the period in seconds used to query the last given number of days,
+// GetMetricStatistics returns at most 1440 datapoints per call
+func metricPeriod(days int) int64 {
+	return int64(3600 * (days/60 + 1))
+}
+
+// sumMetric returns the sum of the metric for the last given number of days
+func sumMetric(cwSvc *cloudwatch.CloudWatch, namespace, metricName string, dimensions []*cloudwatch.Dimension, days int) (float64, error) {
+	datapoints, err := getMetricStatistics(cwSvc, namespace, metricName, "Sum", dimensions, days)
+	if err != nil {
+		return 0, err
+	}
+	sum := 0.0
+	for _, datapoint := range datapoints {
+		sum = sum + *datapoint.Sum
+	}
+	return sum, nil
+}
+
+// maxMetric returns the maximum of the metric for the last given number of days
+func maxMetric(cwSvc *cloudwatch.CloudWatch, namespace, metricName string, dimensions []*cloudwatch.Dimension, days int) (float64, error) {
+	datapoints, err := getMetricStatistics(cwSvc, namespace, metricName, "Maximum", dimensions, days)
+	if err != nil {
+		return 0, err
+	}
+	max := 0.0
+	for _, datapoint := range datapoints {
+		if *datapoint.Maximum > max {
+			max = *datapoint.Maximum
+		}
+	}
+	return max, nil
+}
+
+// averageMetric returns the average of the metric for the last given number of days,
+// ok is false when there are no datapoints
+func averageMetric(cwSvc *cloudwatch.CloudWatch, namespace, metricName string, dimensions []*cloudwatch.Dimension, days int) (float64, bool, error) {
+	datapoints, err := getMetricStatistics(cwSvc, namespace, metricName, "Average", dimensions, days)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(datapoints) == 0 {
+		return 0, false, nil
+	}
+	sum := 0.0
+	for _, datapoint := range datapoints {
+		sum = sum + *datapoint.Average
+	}
+	return sum / float64(len(datapoints)), true, nil
+}
+
+// Retention of high resolution datapoints, 1 minute datapoints are kept for 15 days and 5 minute ones for 63 days
+const (
+	oneMinuteRetentionDays  = 15
+	fiveMinuteRetentionDays = 63
+)
+
+// peakMetricRate returns the highest per second rate of the metrics summed within a single period
+// for the last given number of days. The period should be 60 or 300 seconds so that short bursts
+// are not averaged out, the window is capped to the retention of datapoints with that period
+func peakMetricRate(cwSvc *cloudwatch.CloudWatch, namespace string, metricNames []string, dimensions []*cloudwatch.Dimension, period int64, days int) (float64, error) {
+	retentionDays := fiveMinuteRetentionDays
+	if period < 300 {
+		retentionDays = oneMinuteRetentionDays
+	}
+	if days > retentionDays {
+		days = retentionDays
+	}
+	endTime := time.Now()
+	startTime := endTime.AddDate(0, 0, -days)
+
+	queries := make([]*cloudwatch.MetricDataQuery, 0, len(metricNames))
+	for i, metricName := range metricNames {
+		queries = append(queries, &cloudwatch.MetricDataQuery{
+			Id: aws.String(fmt.Sprint("m", i)),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: &cloudwatch.Metric{
+					Namespace:  aws.String(namespace),
+					MetricName: aws.String(metricName),
+					Dimensions: dimensions,
+				},
+				Period: aws.Int64(period),
+				Stat:   aws.String("Sum"),
+			},
+		})
+	}
+	input := &cloudwatch.GetMetricDataInput{
+		MetricDataQueries: queries,
+		StartTime:         &startTime,
+		EndTime:           &endTime,
+	}
+
+	sums := make(map[int64]float64)
+	err := cwSvc.GetMetricDataPages(input, func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+		for _, result := range page.MetricDataResults {
+			for i, timestamp := range result.Timestamps {
+				sums[timestamp.Unix()] += aws.Float64Value(result.Values[i])
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	max := 0.0
+	for _, sum := range sums {
+		if sum > max {
+			max = sum
+		}
+	}
+	return max / float64(period), nil
+}
+
+// percentileMetric returns the percentile, e.g. p95, of the metric over the last given number of days,
+// ok is false when there are no datapoints
+func percentileMetric(cwSvc *cloudwatch.CloudWatch, namespace, metricName, percentile string, dimensions []*cloudwatch.Dimension, days int) (float64, bool, error) {
+	endTime := time.Now()
+	startTime := endTime.AddDate(0, 0, -days)
+	// a single period covering the whole window
+	period := int64(days * 24 * 3600)
+
+	metricInput := &cloudwatch.GetMetricStatisticsInput{
+		MetricName:         aws.String(metricName),
+		Namespace:          aws.String(namespace),
+		ExtendedStatistics: aws.StringSlice([]string{percentile}),
+		Dimensions:         dimensions,
+		StartTime:          &startTime,
+		EndTime:            &endTime,
+		Period:             &period,
+	}
+	metricOutput, err := cwSvc.GetMetricStatistics(metricInput)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, datapoint := range metricOutput.Datapoints {
+		if value, ok := datapoint.ExtendedStatistics[percentile]; ok && value != nil {
+			return *value, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func newDimension(name string, value *string) []*cloudwatch.Dimension {
+	return []*cloudwatch.Dimension{{
+		Name:  aws.String(name),
+		Value: value,
+	}}
+}
+
+func dimensionValue(dimensions []*cloudwatch.Dimension, name string) string {
+	for _, dimension := range dimensions {
+		if aws.StringValue(dimension.Name) == name {
+			return aws.StringValue(dimension.Value)
+		}
+	}
+	return ""
+}
+
+// listAgentMetrics returns the CloudWatch agent metrics of the instance with their full dimension sets,
+// the agent publishes metrics with additional dimensions such as ImageId and InstanceType
+func listAgentMetrics(cwSvc *cloudwatch.CloudWatch, metricName, instanceID string) ([]*cloudwatch.Metric, error) {
+	input := &cloudwatch.ListMetricsInput{
+		Namespace:  aws.String("CWAgent"),
+		MetricName: aws.String(metricName),
+		Dimensions: []*cloudwatch.DimensionFilter{{
+			Name:  aws.String("InstanceId"),
+			Value: aws.String(instanceID),
+		}},
+	}
+	metrics := make([]*cloudwatch.Metric, 0)
+	err := cwSvc.ListMetricsPages(input, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+		metrics = append(metrics, page.Metrics...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing CloudWatch agent metrics for %v: %w", instanceID, err)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import "fmt"
+
+// hoursPerMonth is the number of hours AWS uses for monthly price estimates
+const hoursPerMonth = 730
+
+// On-demand prices in us-east-1, used for rough cost estimates
+const (
+	natGatewayHourlyPrice = 0.045
+	natGatewayPerGBPrice  = 0.045
+	albHourlyPrice        = 0.0225
+	nlbHourlyPrice        = 0.0225
+	gwlbHourlyPrice       = 0.0125
+	classicELBHourlyPrice = 0.025
+	publicIPv4HourlyPrice = 0.005
+	eksClusterHourlyPrice = 0.10
+)
+
+// VPC networking prices per hour, VPC endpoints are billed per AZ
+const (
+	vpcEndpointHourlyPrice              = 0.01
+	vpnConnectionHourlyPrice            = 0.05
+	transitGatewayAttachmentHourlyPrice = 0.05
+)
+
+// DynamoDB prices per provisioned capacity unit-hour and per million on-demand request units
+const (
+	dynamoDBRCUHourlyPrice    = 0.00013
+	dynamoDBWCUHourlyPrice    = 0.00065
+	dynamoDBReadRequestPrice  = 0.25
+	dynamoDBWriteRequestPrice = 1.25
+)
+
+// EBS prices per GB-month, per provisioned IOPS-month and per provisioned MB/s-month
+const (
+	gp2GBMonthPrice           = 0.10
+	gp3GBMonthPrice           = 0.08
+	io1GBMonthPrice           = 0.125
+	io2GBMonthPrice           = 0.125
+	st1GBMonthPrice           = 0.045
+	sc1GBMonthPrice           = 0.015
+	standardGBMonthPrice      = 0.05
+	gp3IOPSMonthPrice         = 0.005
+	gp3ThroughputMonthPrice   = 0.04
+	io1IOPSMonthPrice         = 0.065
+	io2IOPSMonthPriceTier1    = 0.065
+	io2IOPSMonthPriceTier2    = 0.0455
+	io2IOPSMonthPriceTier3    = 0.032
+	gp3BaselineIOPS           = 3000
+	gp3BaselineThroughputMBps = 125
+)
+
+func formatHourlyCost(hourly float64) string {
+	return fmt.Sprintf("$%.3f/hour ($%.2f/month)", hourly, hourly*hoursPerMonth)
+}
+
+func formatMonthlyCost(monthly float64) string {
+	return fmt.Sprintf("$%.2f/month", monthly)
+}
+
+// logsGBMonthPrice is the CloudWatch Logs archived storage price per GB-month
+const logsGBMonthPrice = 0.03
+
+// ecrGBMonthPrice is the ECR storage price per GB-month
+const ecrGBMonthPrice = 0.10
+
+// redshiftSnapshotGBMonthPrice is the Redshift manual snapshot storage price per GB-month
+const redshiftSnapshotGBMonthPrice = 0.024
+
+// Monthly prices of a customer managed KMS key and a Secrets Manager secret
+const (
+	kmsKeyMonthlyPrice = 1.0
+	secretMonthlyPrice = 0.40
+)
+
+// s3StorageGBMonthPrices maps S3 CloudWatch storage types to their price per GB-month
+var s3StorageGBMonthPrices = map[string]float64{
+	"StandardStorage":             0.023,
+	"IntelligentTieringFAStorage": 0.023,
+	"IntelligentTieringIAStorage": 0.0125,
+	"StandardIAStorage":           0.0125,
+	"OneZoneIAStorage":            0.01,
+	"ReducedRedundancyStorage":    0.024,
+	"GlacierStorage":              0.004,
+	"GlacierStagingStorage":       0.004,
+	"GlacierObjectOverhead":       0.004,
+	"DeepArchiveStorage":          0.00099,
+	"DeepArchiveStagingStorage":   0.00099,
+	"DeepArchiveObjectOverhead":   0.00099,
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// underusedCapacityRatio is the share of provisioned capacity below which the average
+// consumed capacity has to stay for a table to be reported as over-provisioned
+const underusedCapacityRatio = 0.2
+
+// ListIdleDynamoDBTables lists DynamoDB tables with no consumed capacity, over-provisioned tables
+// and unused global secondary indexes for the last given number of days
+func ListIdleDynamoDBTables(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	dynamoDBSvc := dynamodb.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	tables, err := describeTables(dynamoDBSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	idleList := make([]string, 0)
+	overProvisionedList := make([]string, 0)
+	unusedIndexList := make([]string, 0)
+	for _, table := range tables {
+		dimensions := newDimension("TableName", table.TableName)
+		consumedRead, err := sumMetric(cwSvc, "AWS/DynamoDB", "ConsumedReadCapacityUnits", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *table.TableName, err)
+		}
+		consumedWrite, err := sumMetric(cwSvc, "AWS/DynamoDB", "ConsumedWriteCapacityUnits", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *table.TableName, err)
+		}
+
+		provisioned := isProvisionedTable(table)
+		tableEntry := fmt.Sprint(*table.TableName, ", items: ", aws.Int64Value(table.ItemCount),
+			", size: ", aws.Int64Value(table.TableSizeBytes), " bytes")
+		if provisioned {
+			tableEntry = fmt.Sprint(tableEntry, ", provisioned rcu: ", *table.ProvisionedThroughput.ReadCapacityUnits,
+				", wcu: ", *table.ProvisionedThroughput.WriteCapacityUnits)
+		}
+
+		if consumedRead == 0 && consumedWrite == 0 {
+			if provisioned {
+				tableEntry = fmt.Sprint(tableEntry, ", cost: ", formatMonthlyCost(provisionedCapacityMonthlyCost(table.ProvisionedThroughput)))
+			}
+			idleList = append(idleList, tableEntry)
+			continue
+		}
+
+		if provisioned {
+			recommendation, err := recommendTableCapacity(cwSvc, table, consumedRead, consumedWrite, days)
+			if err != nil {
+				return nil, err
+			}
+			if recommendation != "" {
+				overProvisionedList = append(overProvisionedList, tableEntry+", "+recommendation)
+			}
+		}
+
+		for _, index := range table.GlobalSecondaryIndexes {
+			indexDimensions := append(newDimension("TableName", table.TableName), newDimension("GlobalSecondaryIndexName", index.IndexName)...)
+			indexRead, err := sumMetric(cwSvc, "AWS/DynamoDB", "ConsumedReadCapacityUnits", indexDimensions, days)
+			if err != nil {
+				return nil, fmt.Errorf("error getting %v metrics: %w", *index.IndexName, err)
+			}
+			if indexRead == 0 {
+				indexEntry := fmt.Sprint(*table.TableName, "/", *index.IndexName, ", size: ", aws.Int64Value(index.IndexSizeBytes), " bytes")
+				if provisioned && index.ProvisionedThroughput != nil {
+					indexCost := provisionedCapacityMonthlyCost(&dynamodb.ProvisionedThroughputDescription{
+						ReadCapacityUnits:  index.ProvisionedThroughput.ReadCapacityUnits,
+						WriteCapacityUnits: index.ProvisionedThroughput.WriteCapacityUnits,
+					})
+					indexEntry = fmt.Sprint(indexEntry, ", cost: ", formatMonthlyCost(indexCost))
+				}
+				unusedIndexList = append(unusedIndexList, indexEntry)
+			}
+		}
+	}
+
+	return []Result{
+		{"Idle DynamoDB tables:", idleList},
+		{"Over-provisioned DynamoDB tables:", overProvisionedList},
+		{"Unused DynamoDB global secondary indexes:", unusedIndexList},
+	}, nil
+}
+
+func isProvisionedTable(table *dynamodb.TableDescription) bool {
+	// tables created before on-demand capacity was introduced have no billing mode summary
+	if table.BillingModeSummary != nil && aws.StringValue(table.BillingModeSummary.BillingMode) == dynamodb.BillingModePayPerRequest {
+		return false
+	}
+	return table.ProvisionedThroughput != nil
+}
+
+// recommendTableCapacity compares the consumed and provisioned capacity of the table and returns
+// the cheaper of on-demand or lower provisioned capacity, or an empty string if the table is well used
+func recommendTableCapacity(cwSvc *cloudwatch.CloudWatch, table *dynamodb.TableDescription, consumedRead, consumedWrite float64, days int) (string, error) {
+	seconds := float64(days * 24 * 3600)
+	provisionedRead := float64(*table.ProvisionedThroughput.ReadCapacityUnits)
+	provisionedWrite := float64(*table.ProvisionedThroughput.WriteCapacityUnits)
+	if consumedRead/seconds >= provisionedRead*underusedCapacityRatio || consumedWrite/seconds >= provisionedWrite*underusedCapacityRatio {
+		return "", nil
+	}
+
+	// provisioned capacity has to cover the per minute peak, hourly averages hide bursts which would be throttled
+	dimensions := newDimension("TableName", table.TableName)
+	peakRead, err := peakMetricRate(cwSvc, "AWS/DynamoDB", []string{"ConsumedReadCapacityUnits"}, dimensions, peakPeriodSeconds, days)
+	if err != nil {
+		return "", fmt.Errorf("error getting %v metrics: %w", *table.TableName, err)
+	}
+	peakWrite, err := peakMetricRate(cwSvc, "AWS/DynamoDB", []string{"ConsumedWriteCapacityUnits"}, dimensions, peakPeriodSeconds, days)
+	if err != nil {
+		return "", fmt.Errorf("error getting %v metrics: %w", *table.TableName, err)
+	}
+	recommended := &dynamodb.ProvisionedThroughputDescription{
+		ReadCapacityUnits:  aws.Int64(int64(math.Max(1, math.Ceil(peakRead*provisionedHeadroom)))),
+		WriteCapacityUnits: aws.Int64(int64(math.Max(1, math.Ceil(peakWrite*provisionedHeadroom)))),
+	}
+
+	currentCost := provisionedCapacityMonthlyCost(table.ProvisionedThroughput)
+	provisionedSavings := currentCost - provisionedCapacityMonthlyCost(recommended)
+	// consumed capacity units over the window roughly match the request units billed on-demand
+	monthlyShare := hoursPerMonth * 3600 / seconds
+	onDemandCost := (consumedRead*dynamoDBReadRequestPrice + consumedWrite*dynamoDBWriteRequestPrice) / 1000000 * monthlyShare
+	onDemandSavings := currentCost - onDemandCost
+
+	if onDemandSavings > provisionedSavings {
+		return fmt.Sprint("switch to on-demand, savings: ", formatMonthlyCost(onDemandSavings)), nil
+	}
+	return fmt.Sprint("recommended rcu: ", *recommended.ReadCapacityUnits, ", wcu: ", *recommended.WriteCapacityUnits,
+		", savings: ", formatMonthlyCost(provisionedSavings)), nil
+}
+
+func provisionedCapacityMonthlyCost(throughput *dynamodb.ProvisionedThroughputDescription) float64 {
+	read := float64(aws.Int64Value(throughput.ReadCapacityUnits))
+	write := float64(aws.Int64Value(throughput.WriteCapacityUnits))
+	return (read*dynamoDBRCUHourlyPrice + write*dynamoDBWCUHourlyPrice) * hoursPerMonth
+}
+
+func describeTables(dynamoDBSvc *dynamodb.DynamoDB) ([]*dynamodb.TableDescription, error) {
+	tableNames := make([]*string, 0)
+	err := dynamoDBSvc.ListTablesPages(&dynamodb.ListTablesInput{}, func(page *dynamodb.ListTablesOutput, lastPage bool) bool {
+		tableNames = append(tableNames, page.TableNames...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing DynamoDB tables: %w", err)
+	}
+
+	tables := make([]*dynamodb.TableDescription, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		output, err := dynamoDBSvc.DescribeTable(&dynamodb.DescribeTableInput{TableName: tableName})
+		if err != nil {
+			return nil, fmt.Errorf("error describing DynamoDB table %v: %w", *tableName, err)
+		}
+		tables = append(tables, output.Table)
+	}
+
+	return tables, nil
+}
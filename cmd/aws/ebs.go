@@ -20,13 +20,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/aws"
 )
 
-// ListIdleEBSs lists idle EBS volumes
-func ListIdleEBSs() ([]Result, error) {
+// ListIdleEBSs lists EBS volumes with no read or write operations for the last given number of days
+func ListIdleEBSs(days int) ([]Result, error) {
 	sess, err := newSession()
 	if err != nil {
 		return nil, err
@@ -45,43 +46,17 @@ func ListIdleEBSs() ([]Result, error) {
 	ebsList := make([]string, 0)
 	for _, volume := range volumes {
 		if !isRootVolume(volume.Attachments) {
-			endTime := time.Now()
-			startTime := time.Now().AddDate(0, 0, -7)
-			period := int64(3600)
-			dimension := cloudwatch.Dimension{
-				Name:  aws.String("VolumeId"),
-				Value: volume.VolumeId,
-			}
-
-			metricInput := &cloudwatch.GetMetricStatisticsInput{
-				MetricName: aws.String("VolumeReadOps"),
-				Namespace:  aws.String("AWS/EBS"),
-				Statistics: aws.StringSlice([]string{"Sum"}),
-				Dimensions: []*cloudwatch.Dimension{&dimension},
-				StartTime:  &startTime,
-				EndTime:    &endTime,
-				Period:     &period,
-			}
-			metricOutput, err := cwSvc.GetMetricStatistics(metricInput)
+			dimensions := newDimension("VolumeId", volume.VolumeId)
+			count, err := sumMetric(cwSvc, "AWS/EBS", "VolumeReadOps", dimensions, days)
 			if err != nil {
 				return nil, err
 			}
-			count := 0.0
-			for _, datapoint := range metricOutput.Datapoints {
-				count = count + *datapoint.Sum
-			}
 			if count <= 1 {
-				metricInput.MetricName = aws.String("VolumeWriteOps")
-				metricOutput, err := cwSvc.GetMetricStatistics(metricInput)
+				count, err := sumMetric(cwSvc, "AWS/EBS", "VolumeWriteOps", dimensions, days)
 				if err != nil {
 					return nil, err
 				}
-				count := 0.0
-				for _, datapoint := range metricOutput.Datapoints {
-					count = count + *datapoint.Sum
-				}
 				if count <= 1 {
-					// fmt.Println("No Write Ops: ", *attachment.Device, ", ", *attachment.VolumeId)
 					ebsList = append(ebsList, *volume.VolumeId)
 				}
 			}
@@ -120,6 +95,7 @@ func listAvailableEBSs() ([]Result, error) {
 		return nil, err
 	}
 	ec2Svc := ec2.New(sess)
+	ctSvc := cloudtrail.New(sess)
 
 	filter := &ec2.Filter{
 		Name:   aws.String("status"),
@@ -130,17 +106,20 @@ func listAvailableEBSs() ([]Result, error) {
 		return nil, fmt.Errorf("error describing EBSs: %w", err)
 	}
 
+	detachEvents := make(map[string]*cloudtrail.Event)
+	if len(volumes) > 0 {
+		detachEvents, err = lookupDetachEvents(ctSvc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	ebsList := make([]string, 0)
 	for _, volume := range volumes {
-		var ebsName *string
-		for _, tag := range volume.Tags {
-			if *tag.Key == "Name" {
-				ebsName = tag.Value
-			}
-		}
-		ebsEntry := *volume.VolumeId
-		if ebsName != nil {
-			ebsEntry = ebsEntry + ", " + *ebsName
+		ebsEntry := formatUnusedVolume(volume)
+		if detachEvent, ok := detachEvents[*volume.VolumeId]; ok {
+			ebsEntry = fmt.Sprint(ebsEntry, ", detached: ", detachEvent.EventTime.Format(time.RFC3339),
+				" by ", aws.StringValue(detachEvent.Username))
 		}
 		ebsList = append(ebsList, ebsEntry)
 	}
@@ -168,23 +147,51 @@ func listEBSsOnStoppedEC2() ([]Result, error) {
 
 	ebsList := make([]string, 0)
 	for _, volume := range volumes {
-		var name *string
-		for _, tag := range volume.Tags {
-			if *tag.Key == "Name" {
-				name = tag.Value
-			}
-		}
-
-		ebsEntry := *volume.VolumeId + ", type: " + *volume.VolumeType
-		if name != nil {
-			ebsEntry = *name + ", " + ebsEntry
-		}
-		ebsList = append(ebsList, ebsEntry)
+		ebsList = append(ebsList, formatUnusedVolume(volume))
 	}
 
 	return []Result{{"EBS volumes on stopped EC2:", ebsList}}, nil
 }
 
+func formatUnusedVolume(volume *ec2.Volume) string {
+	return fmt.Sprint(formatVolume(volume), ", az: ", *volume.AvailabilityZone,
+		", created: ", volume.CreateTime.Format(time.RFC3339), ", encrypted: ", aws.BoolValue(volume.Encrypted),
+		", cost: ", formatMonthlyCost(ebsMonthlyCost(volume)))
+}
+
+// lookupDetachEvents returns the latest DetachVolume CloudTrail event by volume ID within
+// the CloudTrail event history retention of 90 days. A single query is used for all volumes
+// as LookupEvents is limited to 2 requests per second
+func lookupDetachEvents(ctSvc *cloudtrail.CloudTrail) (map[string]*cloudtrail.Event, error) {
+	input := &cloudtrail.LookupEventsInput{
+		LookupAttributes: []*cloudtrail.LookupAttribute{{
+			AttributeKey:   aws.String(cloudtrail.LookupAttributeKeyEventName),
+			AttributeValue: aws.String("DetachVolume"),
+		}},
+		StartTime: aws.Time(time.Now().AddDate(0, 0, -90)),
+	}
+	detachEvents := make(map[string]*cloudtrail.Event)
+	// events are returned newest first
+	err := ctSvc.LookupEventsPages(input, func(page *cloudtrail.LookupEventsOutput, lastPage bool) bool {
+		for _, event := range page.Events {
+			for _, resource := range event.Resources {
+				volumeID := aws.StringValue(resource.ResourceName)
+				if aws.StringValue(resource.ResourceType) != "AWS::EC2::Volume" {
+					continue
+				}
+				if _, ok := detachEvents[volumeID]; !ok {
+					detachEvents[volumeID] = event
+				}
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error looking up DetachVolume CloudTrail events: %w", err)
+	}
+	return detachEvents, nil
+}
+
 func getVolumeIDsOnStoppedEC2(ec2Svc *ec2.EC2) ([]*string, error) {
 	filter := &ec2.Filter{
 		Name:   aws.String("instance-state-name"),
@@ -208,7 +215,7 @@ func getVolumeIDsOnStoppedEC2(ec2Svc *ec2.EC2) ([]*string, error) {
 
 func describeVolumes(ids []*string, filters []*ec2.Filter, ec2Svc *ec2.EC2) ([]*ec2.Volume, error) {
 	volumes := make([]*ec2.Volume, 0)
-	if len(ids) == 0 {
+	if ids != nil && len(ids) == 0 {
 		return volumes, nil
 	}
 
@@ -219,7 +226,7 @@ func describeVolumes(ids []*string, filters []*ec2.Filter, ec2Svc *ec2.EC2) ([]*
 
 	err := ec2Svc.DescribeVolumesPages(volumesInput, func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
 		volumes = append(volumes, page.Volumes...)
-		return lastPage
+		return !lastPage
 	})
 
 	return volumes, err
@@ -0,0 +1,254 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	// provisionedHeadroom is applied on top of the peak consumption when recommending capacity
+	provisionedHeadroom = 1.2
+	// overProvisionedRatio is the share of provisioned IOPS or throughput below which the
+	// recommended capacity has to fall for the volume to be reported
+	overProvisionedRatio = 0.5
+	// oversizedUsedPercent is the disk usage below which a volume is considered oversized
+	oversizedUsedPercent = 30
+	minOversizedVolumeGB = 50
+	// peakPeriodSeconds is the resolution peak IOPS and throughput are measured at
+	peakPeriodSeconds = 60
+)
+
+// OptimizeEBSs recommends EBS volume type migrations, lower provisioned IOPS and throughput
+// and smaller volumes based on the usage for the last given number of days
+func OptimizeEBSs(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ec2Svc := ec2.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	volumes, err := describeVolumes(nil, nil, ec2Svc)
+	if err != nil {
+		return nil, fmt.Errorf("error describing EBSs: %w", err)
+	}
+
+	volumesPerInstance := make(map[string]int)
+	for _, volume := range volumes {
+		for _, attachment := range volume.Attachments {
+			volumesPerInstance[*attachment.InstanceId]++
+		}
+	}
+
+	gp3List := make([]string, 0)
+	io2List := make([]string, 0)
+	overProvisionedList := make([]string, 0)
+	oversizedList := make([]string, 0)
+	for _, volume := range volumes {
+		volumeType := aws.StringValue(volume.VolumeType)
+		switch volumeType {
+		case ec2.VolumeTypeGp2:
+			gp3 := gp2ToGp3(volume)
+			if savings := ebsMonthlyCost(volume) - ebsMonthlyCost(gp3); savings > 0 {
+				gp3List = append(gp3List, fmt.Sprint(formatVolume(volume), ", savings: ", formatMonthlyCost(savings)))
+			}
+		case ec2.VolumeTypeIo1:
+			io2 := *volume
+			io2.VolumeType = aws.String(ec2.VolumeTypeIo2)
+			if savings := ebsMonthlyCost(volume) - ebsMonthlyCost(&io2); savings > 0 {
+				io2List = append(io2List, fmt.Sprint(formatVolume(volume), ", savings: ", formatMonthlyCost(savings)))
+			}
+		}
+
+		if len(volume.Attachments) == 0 {
+			continue
+		}
+
+		switch volumeType {
+		case ec2.VolumeTypeIo1, ec2.VolumeTypeIo2, ec2.VolumeTypeGp3:
+			recommendation, err := recommendProvisionedCapacity(cwSvc, volume, days)
+			if err != nil {
+				return nil, err
+			}
+			if recommendation != nil {
+				savings := ebsMonthlyCost(volume) - ebsMonthlyCost(recommendation)
+				entry := fmt.Sprint(formatVolume(volume), ", recommended iops: ", aws.Int64Value(recommendation.Iops))
+				if volumeType == ec2.VolumeTypeGp3 {
+					entry = fmt.Sprint(entry, ", recommended throughput: ", aws.Int64Value(recommendation.Throughput), " MB/s")
+				}
+				overProvisionedList = append(overProvisionedList, fmt.Sprint(entry, ", savings: ", formatMonthlyCost(savings)))
+			}
+		}
+
+		instanceID := *volume.Attachments[0].InstanceId
+		if volumesPerInstance[instanceID] != 1 || *volume.Size < minOversizedVolumeGB {
+			// disk usage reported by the CloudWatch agent can only be matched to the volume
+			// when it is the only one attached to the instance
+			continue
+		}
+		usedPercent, ok, err := maxDiskUsedPercent(cwSvc, instanceID, days)
+		if err != nil {
+			return nil, err
+		}
+		if ok && usedPercent < oversizedUsedPercent {
+			smaller := *volume
+			usedGB := math.Ceil(float64(*volume.Size) * usedPercent / 100)
+			recommendedGB := math.Ceil(float64(*volume.Size) * usedPercent / 100 * 2)
+			// never recommend less than the used space or the 1 GiB minimum volume size
+			smaller.Size = aws.Int64(int64(math.Max(math.Max(recommendedGB, usedGB), 1)))
+			savings := ebsMonthlyCost(volume) - ebsMonthlyCost(&smaller)
+			oversizedEntry := fmt.Sprintf("%s, used: %.0f%%, recommended size: %d GiB, savings: %s",
+				formatVolume(volume), usedPercent, *smaller.Size, formatMonthlyCost(savings))
+			oversizedList = append(oversizedList, oversizedEntry)
+		}
+	}
+
+	return []Result{
+		{"gp2 EBS volumes to migrate to gp3:", gp3List},
+		{"io1 EBS volumes to migrate to io2:", io2List},
+		{"EBS volumes with over-provisioned IOPS or throughput:", overProvisionedList},
+		{"Oversized EBS volumes:", oversizedList},
+	}, nil
+}
+
+func formatVolume(volume *ec2.Volume) string {
+	volumeEntry := fmt.Sprint(*volume.VolumeId, ", type: ", *volume.VolumeType, ", size: ", *volume.Size, " GiB")
+	if volume.Iops != nil {
+		volumeEntry = fmt.Sprint(volumeEntry, ", iops: ", *volume.Iops)
+	}
+	if volume.Throughput != nil {
+		volumeEntry = fmt.Sprint(volumeEntry, ", throughput: ", *volume.Throughput, " MB/s")
+	}
+	if name := getNameTag(volume.Tags); name != nil {
+		volumeEntry = *name + ", " + volumeEntry
+	}
+	return volumeEntry
+}
+
+// gp2ToGp3 returns the gp3 equivalent of the gp2 volume with the same baseline performance
+func gp2ToGp3(volume *ec2.Volume) *ec2.Volume {
+	iops := aws.Int64Value(volume.Iops)
+	if iops < gp3BaselineIOPS {
+		iops = gp3BaselineIOPS
+	}
+	// gp2 volumes larger than 170 GiB deliver up to 250 MB/s
+	throughput := int64(gp3BaselineThroughputMBps)
+	if *volume.Size > 170 {
+		throughput = 250
+	}
+
+	gp3 := *volume
+	gp3.VolumeType = aws.String(ec2.VolumeTypeGp3)
+	gp3.Iops = aws.Int64(iops)
+	gp3.Throughput = aws.Int64(throughput)
+	return &gp3
+}
+
+// recommendProvisionedCapacity returns the volume with IOPS and throughput lowered to the peak
+// per minute consumption plus headroom, or nil if the volume is not over-provisioned
+func recommendProvisionedCapacity(cwSvc *cloudwatch.CloudWatch, volume *ec2.Volume, days int) (*ec2.Volume, error) {
+	dimensions := newDimension("VolumeId", volume.VolumeId)
+	peakIOPS, err := peakMetricRate(cwSvc, "AWS/EBS", []string{"VolumeReadOps", "VolumeWriteOps"}, dimensions, peakPeriodSeconds, days)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %v metrics: %w", *volume.VolumeId, err)
+	}
+
+	minIOPS := int64(100)
+	if *volume.VolumeType == ec2.VolumeTypeGp3 {
+		minIOPS = gp3BaselineIOPS
+	}
+	recommended := *volume
+	overProvisioned := false
+	iops := int64(math.Ceil(peakIOPS * provisionedHeadroom))
+	if iops < minIOPS {
+		iops = minIOPS
+	}
+	if float64(iops) < float64(aws.Int64Value(volume.Iops))*overProvisionedRatio {
+		recommended.Iops = aws.Int64(iops)
+		overProvisioned = true
+	}
+
+	if *volume.VolumeType == ec2.VolumeTypeGp3 {
+		peakBytes, err := peakMetricRate(cwSvc, "AWS/EBS", []string{"VolumeReadBytes", "VolumeWriteBytes"}, dimensions, peakPeriodSeconds, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *volume.VolumeId, err)
+		}
+		throughput := int64(math.Ceil(peakBytes / (1024 * 1024) * provisionedHeadroom))
+		if throughput < gp3BaselineThroughputMBps {
+			throughput = gp3BaselineThroughputMBps
+		}
+		if float64(throughput) < float64(aws.Int64Value(volume.Throughput))*overProvisionedRatio {
+			recommended.Throughput = aws.Int64(throughput)
+			overProvisioned = true
+		}
+	}
+
+	if !overProvisioned {
+		return nil, nil
+	}
+	return &recommended, nil
+}
+
+// maxDiskUsedPercent returns the highest disk usage reported by the CloudWatch agent for the instance,
+// ok is false when the agent does not report exactly one disk
+func maxDiskUsedPercent(cwSvc *cloudwatch.CloudWatch, instanceID string, days int) (float64, bool, error) {
+	metrics, err := listAgentMetrics(cwSvc, "disk_used_percent", instanceID)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(metrics) != 1 {
+		return 0, false, nil
+	}
+
+	usedPercent, err := maxMetric(cwSvc, "CWAgent", "disk_used_percent", metrics[0].Dimensions, days)
+	if err != nil {
+		return 0, false, fmt.Errorf("error getting CloudWatch agent metrics for %v: %w", instanceID, err)
+	}
+	return usedPercent, true, nil
+}
+
+// ebsMonthlyCost returns the monthly cost of the volume storage and provisioned performance
+func ebsMonthlyCost(volume *ec2.Volume) float64 {
+	size := float64(aws.Int64Value(volume.Size))
+	iops := float64(aws.Int64Value(volume.Iops))
+	switch aws.StringValue(volume.VolumeType) {
+	case ec2.VolumeTypeGp2:
+		return size * gp2GBMonthPrice
+	case ec2.VolumeTypeGp3:
+		extraIOPS := math.Max(0, iops-gp3BaselineIOPS)
+		extraThroughput := math.Max(0, float64(aws.Int64Value(volume.Throughput))-gp3BaselineThroughputMBps)
+		return size*gp3GBMonthPrice + extraIOPS*gp3IOPSMonthPrice + extraThroughput*gp3ThroughputMonthPrice
+	case ec2.VolumeTypeIo1:
+		return size*io1GBMonthPrice + iops*io1IOPSMonthPrice
+	case ec2.VolumeTypeIo2:
+		tier1 := math.Min(iops, 32000)
+		tier2 := math.Min(math.Max(0, iops-32000), 32000)
+		tier3 := math.Max(0, iops-64000)
+		return size*io2GBMonthPrice + tier1*io2IOPSMonthPriceTier1 + tier2*io2IOPSMonthPriceTier2 + tier3*io2IOPSMonthPriceTier3
+	case ec2.VolumeTypeSt1:
+		return size * st1GBMonthPrice
+	case ec2.VolumeTypeSc1:
+		return size * sc1GBMonthPrice
+	default:
+		return size * standardGBMonthPrice
+	}
+}
@@ -16,12 +16,123 @@ limitations under the License.
 package aws
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
+// stateTransitionTimeRegexp matches the time in a state transition reason like
+// "User initiated (2021-03-01 12:34:56 GMT)"
+var stateTransitionTimeRegexp = regexp.MustCompile(`\((\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) GMT\)`)
+
+// ListStoppedEC2s lists EC2 instances stopped for longer than the given number of days
+// with the resources they keep being charged for
+func ListStoppedEC2s(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ec2Svc := ec2.New(sess)
+
+	filter := &ec2.Filter{
+		Name:   aws.String("instance-state-name"),
+		Values: aws.StringSlice([]string{"stopped"}),
+	}
+	instances, err := describeEC2Instances(nil, []*ec2.Filter{filter}, ec2Svc)
+	if err != nil {
+		return nil, fmt.Errorf("error describing EC2 instances: %w", err)
+	}
+
+	output, err := ec2Svc.DescribeAddresses(&ec2.DescribeAddressesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error describing addresses: %w", err)
+	}
+	addressesPerInstance := make(map[string][]*ec2.Address)
+	for _, address := range output.Addresses {
+		if address.InstanceId != nil {
+			addressesPerInstance[*address.InstanceId] = append(addressesPerInstance[*address.InstanceId], address)
+		}
+	}
+
+	threshold := time.Now().AddDate(0, 0, -days)
+	ec2List := make([]string, 0)
+	unknownList := make([]string, 0)
+	for _, instance := range instances {
+		stoppedAt, ok := parseStateTransitionTime(aws.StringValue(instance.StateTransitionReason))
+		if ok && stoppedAt.After(threshold) {
+			continue
+		}
+
+		volumeIDs := make([]*string, 0)
+		for _, blockDev := range instance.BlockDeviceMappings {
+			if blockDev.Ebs != nil {
+				volumeIDs = append(volumeIDs, blockDev.Ebs.VolumeId)
+			}
+		}
+		volumes, err := describeVolumes(volumeIDs, nil, ec2Svc)
+		if err != nil {
+			return nil, fmt.Errorf("error describing EBS volumes: %w", err)
+		}
+
+		monthlyCost := 0.0
+		volumeEntries := make([]string, 0, len(volumes))
+		for _, volume := range volumes {
+			monthlyCost += ebsMonthlyCost(volume)
+			volumeEntries = append(volumeEntries, fmt.Sprint(*volume.VolumeId, " (", *volume.Size, " GiB)"))
+		}
+		eipEntries := make([]string, 0)
+		for _, address := range addressesPerInstance[*instance.InstanceId] {
+			monthlyCost += publicIPv4HourlyPrice * hoursPerMonth
+			eipEntries = append(eipEntries, *address.PublicIp)
+		}
+		eniEntries := make([]string, 0, len(instance.NetworkInterfaces))
+		for _, eni := range instance.NetworkInterfaces {
+			eniEntries = append(eniEntries, *eni.NetworkInterfaceId)
+		}
+
+		ec2Entry := fmt.Sprint(*instance.InstanceId, ", type: ", *instance.InstanceType,
+			", ebs: [", strings.Join(volumeEntries, " "), "], eip: [", strings.Join(eipEntries, " "),
+			"], eni: [", strings.Join(eniEntries, " "), "], cost: ", formatMonthlyCost(monthlyCost))
+		if name := getNameTag(instance.Tags); name != nil {
+			ec2Entry = *name + ", " + ec2Entry
+		}
+		// the stop time can't be told for some state transition reasons, e.g. instances stopped by AWS,
+		// these are listed apart so they are not mistaken for long stopped ones
+		if !ok {
+			unknownList = append(unknownList, ec2Entry+", reason: "+aws.StringValue(instance.StateTransitionReason))
+			continue
+		}
+		ec2List = append(ec2List, fmt.Sprint(ec2Entry, ", stopped since: ", stoppedAt.Format(time.RFC3339)))
+	}
+
+	return []Result{
+		{fmt.Sprintf("EC2 instances stopped for more than %d days:", days), ec2List},
+		{"Stopped EC2 instances with unknown stop time:", unknownList},
+	}, nil
+}
+
+func parseStateTransitionTime(reason string) (time.Time, bool) {
+	match := stateTransitionTimeRegexp.FindStringSubmatch(reason)
+	if match == nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02 15:04:05", match[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// maxFilterValues is the maximum number of values EC2 accepts in a single filter
+const maxFilterValues = 200
+
 func describeEC2Instances(ids []*string, filters []*ec2.Filter, ec2Svc *ec2.EC2) ([]*ec2.Instance, error) {
 	instancesInput := &ec2.DescribeInstancesInput{
-		Filters: filters,
+		Filters:     filters,
 		InstanceIds: ids,
 	}
 
@@ -30,8 +141,17 @@ func describeEC2Instances(ids []*string, filters []*ec2.Filter, ec2Svc *ec2.EC2)
 		for _, reservation := range page.Reservations {
 			instances = append(instances, reservation.Instances...)
 		}
-		return lastPage
+		return !lastPage
 	})
 
 	return instances, err
 }
+
+func getNameTag(tags []*ec2.Tag) *string {
+	for _, tag := range tags {
+		if *tag.Key == "Name" {
+			return tag.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// ListUnusedECRImages lists ECR repositories without lifecycle policy, untagged images, images not pulled
+// for the last given number of days and images not used by running ECS tasks or Lambda functions
+func ListUnusedECRImages(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ecrSvc := ecr.New(sess)
+
+	repositories, err := describeRepositories(ecrSvc)
+	if err != nil {
+		return nil, err
+	}
+	usedImages, err := getUsedImages(sess)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := time.Now().AddDate(0, 0, -days)
+	noPolicyList := make([]string, 0)
+	untaggedList := make([]string, 0)
+	notPulledList := make([]string, 0)
+	notUsedList := make([]string, 0)
+	reclaimableList := make([]string, 0)
+	untaggedSize, notPulledSize, notUsedSize, reclaimableSize := int64(0), int64(0), int64(0), int64(0)
+	for _, repository := range repositories {
+		_, err := ecrSvc.GetLifecyclePolicy(&ecr.GetLifecyclePolicyInput{RepositoryName: repository.RepositoryName})
+		if isErrorCode(err, ecr.ErrCodeLifecyclePolicyNotFoundException) {
+			noPolicyList = append(noPolicyList, *repository.RepositoryName)
+		} else if err != nil {
+			return nil, fmt.Errorf("error getting lifecycle policy of %v: %w", *repository.RepositoryName, err)
+		}
+
+		images, err := describeImages(ecrSvc, repository.RepositoryName)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range images {
+			size := aws.Int64Value(image.ImageSizeInBytes)
+			imageEntry := formatImage(repository, image)
+
+			untagged := len(image.ImageTags) == 0
+			if untagged {
+				untaggedSize += size
+				untaggedList = append(untaggedList, imageEntry)
+			}
+			lastUsed := image.ImagePushedAt
+			if image.LastRecordedPullTime != nil {
+				lastUsed = image.LastRecordedPullTime
+			}
+			notPulled := lastUsed.Before(threshold)
+			if notPulled {
+				notPulledSize += size
+				notPulledList = append(notPulledList, imageEntry)
+			}
+			notUsed := !isImageUsed(repository, image, usedImages)
+			if notUsed {
+				notUsedSize += size
+				notUsedList = append(notUsedList, imageEntry)
+			}
+			if notUsed && (untagged || notPulled) {
+				reclaimableSize += size
+				reclaimableList = append(reclaimableList, imageEntry)
+			}
+		}
+	}
+
+	return []Result{
+		{"ECR repositories without lifecycle policy:", noPolicyList},
+		{fmt.Sprintf("Untagged ECR images (%d bytes):", untaggedSize), untaggedList},
+		{fmt.Sprintf("ECR images not pulled for %d days (%d bytes):", days, notPulledSize), notPulledList},
+		{fmt.Sprintf("ECR images not used by running ECS tasks or Lambda functions (%d bytes):", notUsedSize), notUsedList},
+		{fmt.Sprintf("Reclaimable ECR images (%d bytes, %s):", reclaimableSize,
+			formatMonthlyCost(float64(reclaimableSize)/bytesPerGB*ecrGBMonthPrice)), reclaimableList},
+	}, nil
+}
+
+func formatImage(repository *ecr.Repository, image *ecr.ImageDetail) string {
+	imageEntry := fmt.Sprint(*repository.RepositoryName, "@", *image.ImageDigest)
+	if len(image.ImageTags) > 0 {
+		imageEntry = fmt.Sprint(imageEntry, ", tags: [", strings.Join(aws.StringValueSlice(image.ImageTags), " "), "]")
+	}
+	imageEntry = fmt.Sprint(imageEntry, ", size: ", aws.Int64Value(image.ImageSizeInBytes), " bytes, pushed: ", image.ImagePushedAt.Format(time.RFC3339))
+	if image.LastRecordedPullTime != nil {
+		imageEntry = fmt.Sprint(imageEntry, ", last pulled: ", image.LastRecordedPullTime.Format(time.RFC3339))
+	}
+	return imageEntry
+}
+
+// isImageUsed reports whether the image is referenced by digest or by one of its tags
+func isImageUsed(repository *ecr.Repository, image *ecr.ImageDetail, usedImages map[string]bool) bool {
+	if usedImages[*image.ImageDigest] {
+		return true
+	}
+	for _, tag := range image.ImageTags {
+		if usedImages[*repository.RepositoryUri+":"+*tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// getUsedImages returns image references, as digests and image URIs, of running ECS tasks, active ECS task definitions
+// and Lambda container image functions
+func getUsedImages(sess *session.Session) (map[string]bool, error) {
+	usedImages := make(map[string]bool)
+	addImage := func(image string) {
+		if i := strings.Index(image, "@"); i >= 0 {
+			usedImages[image[i+1:]] = true
+			return
+		}
+		usedImages[image] = true
+		// images referenced without a tag are pulled with the latest tag
+		if !strings.Contains(image[strings.LastIndex(image, "/")+1:], ":") {
+			usedImages[image+":latest"] = true
+		}
+	}
+
+	ecsSvc := ecs.New(sess)
+	clusterARNs, err := listClusterARNs(ecsSvc)
+	if err != nil {
+		return nil, err
+	}
+	for _, clusterARN := range clusterARNs {
+		tasks, err := describeTasks(ecsSvc, clusterARN, ecs.DesiredStatusRunning)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			for _, container := range task.Containers {
+				if container.ImageDigest != nil {
+					usedImages[*container.ImageDigest] = true
+				}
+				if container.Image != nil {
+					addImage(*container.Image)
+				}
+			}
+		}
+	}
+
+	// images of services scaled to zero and of scheduled tasks are only referenced by task definitions
+	taskDefinitionARNs, err := listActiveTaskDefinitionARNs(ecsSvc)
+	if err != nil {
+		return nil, err
+	}
+	for _, taskDefinitionARN := range taskDefinitionARNs {
+		output, err := ecsSvc.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{TaskDefinition: taskDefinitionARN})
+		if err != nil {
+			return nil, fmt.Errorf("error describing ECS task definition %v: %w", *taskDefinitionARN, err)
+		}
+		for _, container := range output.TaskDefinition.ContainerDefinitions {
+			if container.Image != nil {
+				addImage(*container.Image)
+			}
+		}
+	}
+
+	lambdaSvc := lambda.New(sess)
+	functions, err := listFunctions(lambdaSvc)
+	if err != nil {
+		return nil, err
+	}
+	for _, function := range functions {
+		if aws.StringValue(function.PackageType) != lambda.PackageTypeImage {
+			continue
+		}
+		output, err := lambdaSvc.GetFunction(&lambda.GetFunctionInput{FunctionName: function.FunctionName})
+		if err != nil {
+			return nil, fmt.Errorf("error getting Lambda function %v: %w", *function.FunctionName, err)
+		}
+		if output.Code != nil {
+			if output.Code.ResolvedImageUri != nil {
+				addImage(*output.Code.ResolvedImageUri)
+			}
+			if output.Code.ImageUri != nil {
+				addImage(*output.Code.ImageUri)
+			}
+		}
+	}
+
+	return usedImages, nil
+}
+
+func describeImages(ecrSvc *ecr.ECR, repositoryName *string) ([]*ecr.ImageDetail, error) {
+	images := make([]*ecr.ImageDetail, 0)
+	input := &ecr.DescribeImagesInput{
+		RepositoryName: repositoryName,
+	}
+	err := ecrSvc.DescribeImagesPages(input, func(page *ecr.DescribeImagesOutput, lastPage bool) bool {
+		images = append(images, page.ImageDetails...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing images of %v: %w", *repositoryName, err)
+	}
+	return images, nil
+}
+
+func describeRepositories(ecrSvc *ecr.ECR) ([]*ecr.Repository, error) {
+	repositories := make([]*ecr.Repository, 0)
+	err := ecrSvc.DescribeRepositoriesPages(&ecr.DescribeRepositoriesInput{}, func(page *ecr.DescribeRepositoriesOutput, lastPage bool) bool {
+		repositories = append(repositories, page.Repositories...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing ECR repositories: %w", err)
+	}
+	return repositories, nil
+}
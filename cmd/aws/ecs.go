@@ -0,0 +1,234 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ecs"
+)
+
+// ListIdleECSResources lists ECS services scaled to zero which still hold load balancers
+// and ECS clusters with registered container instances but no services or tasks,
+// neither of them having run tasks for the last given number of days
+func ListIdleECSResources(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ecsSvc := ecs.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	clusterARNs, err := listClusterARNs(ecsSvc)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := describeClusters(ecsSvc, clusterARNs)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceList := make([]string, 0)
+	clusterList := make([]string, 0)
+	for _, cluster := range clusters {
+		if aws.Int64Value(cluster.ActiveServicesCount) == 0 && aws.Int64Value(cluster.RunningTasksCount) == 0 &&
+			aws.Int64Value(cluster.PendingTasksCount) == 0 && aws.Int64Value(cluster.RegisteredContainerInstancesCount) > 0 {
+			// CPU is reserved on container instances only while tasks run on them
+			reservation, err := maxMetric(cwSvc, "AWS/ECS", "CPUReservation", newDimension("ClusterName", cluster.ClusterName), days)
+			if err != nil {
+				return nil, fmt.Errorf("error getting %v metrics: %w", *cluster.ClusterName, err)
+			}
+			if reservation == 0 {
+				clusterEntry := fmt.Sprint(*cluster.ClusterName, ", container instances: ", *cluster.RegisteredContainerInstancesCount)
+				clusterList = append(clusterList, clusterEntry)
+			}
+		}
+
+		services, err := describeServices(ecsSvc, cluster.ClusterArn)
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range services {
+			if aws.Int64Value(service.DesiredCount) != 0 || len(service.LoadBalancers) == 0 {
+				continue
+			}
+			// services scaled to zero only recently still report the utilization of their last tasks
+			dimensions := append(newDimension("ClusterName", cluster.ClusterName), newDimension("ServiceName", service.ServiceName)...)
+			utilization, err := maxMetric(cwSvc, "AWS/ECS", "CPUUtilization", dimensions, days)
+			if err != nil {
+				return nil, fmt.Errorf("error getting %v metrics: %w", *service.ServiceName, err)
+			}
+			if utilization > 0 {
+				continue
+			}
+			loadBalancers := make([]string, 0, len(service.LoadBalancers))
+			for _, lb := range service.LoadBalancers {
+				if lb.TargetGroupArn != nil {
+					loadBalancers = append(loadBalancers, *lb.TargetGroupArn)
+				} else {
+					loadBalancers = append(loadBalancers, aws.StringValue(lb.LoadBalancerName))
+				}
+			}
+			serviceEntry := fmt.Sprint(*cluster.ClusterName, "/", *service.ServiceName, ", load balancers: [", strings.Join(loadBalancers, " "), "]")
+			serviceList = append(serviceList, serviceEntry)
+		}
+	}
+
+	return []Result{
+		{"ECS services with zero desired count holding load balancers:", serviceList},
+		{"ECS clusters with container instances but no services or tasks:", clusterList},
+	}, nil
+}
+
+func describeClusters(ecsSvc *ecs.ECS, clusterARNs []*string) ([]*ecs.Cluster, error) {
+	// DescribeClusters accepts up to 100 clusters
+	const batchSize = 100
+	clusters := make([]*ecs.Cluster, 0, len(clusterARNs))
+	for start := 0; start < len(clusterARNs); start += batchSize {
+		end := start + batchSize
+		if end > len(clusterARNs) {
+			end = len(clusterARNs)
+		}
+		output, err := ecsSvc.DescribeClusters(&ecs.DescribeClustersInput{Clusters: clusterARNs[start:end]})
+		if err != nil {
+			return nil, fmt.Errorf("error describing ECS clusters: %w", err)
+		}
+		clusters = append(clusters, output.Clusters...)
+	}
+	return clusters, nil
+}
+
+func describeServices(ecsSvc *ecs.ECS, clusterARN *string) ([]*ecs.Service, error) {
+	serviceARNs := make([]*string, 0)
+	input := &ecs.ListServicesInput{
+		Cluster: clusterARN,
+	}
+	err := ecsSvc.ListServicesPages(input, func(page *ecs.ListServicesOutput, lastPage bool) bool {
+		serviceARNs = append(serviceARNs, page.ServiceArns...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing services of %v: %w", *clusterARN, err)
+	}
+
+	// DescribeServices accepts up to 10 services
+	const batchSize = 10
+	services := make([]*ecs.Service, 0, len(serviceARNs))
+	for start := 0; start < len(serviceARNs); start += batchSize {
+		end := start + batchSize
+		if end > len(serviceARNs) {
+			end = len(serviceARNs)
+		}
+		output, err := ecsSvc.DescribeServices(&ecs.DescribeServicesInput{
+			Cluster:  clusterARN,
+			Services: serviceARNs[start:end],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing services of %v: %w", *clusterARN, err)
+		}
+		services = append(services, output.Services...)
+	}
+	return services, nil
+}
+
+// listClusterARNs returns ARNs of all ECS clusters
+func listClusterARNs(ecsSvc *ecs.ECS) ([]*string, error) {
+	clusterARNs := make([]*string, 0)
+	err := ecsSvc.ListClustersPages(&ecs.ListClustersInput{}, func(page *ecs.ListClustersOutput, lastPage bool) bool {
+		clusterARNs = append(clusterARNs, page.ClusterArns...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing ECS clusters: %w", err)
+	}
+	return clusterARNs, nil
+}
+
+// listActiveTaskDefinitionFamilies returns names of task definition families with an active revision
+func listActiveTaskDefinitionFamilies(ecsSvc *ecs.ECS) (map[string]bool, error) {
+	families := make(map[string]bool)
+	input := &ecs.ListTaskDefinitionFamiliesInput{
+		Status: aws.String(ecs.TaskDefinitionFamilyStatusActive),
+	}
+	err := ecsSvc.ListTaskDefinitionFamiliesPages(input, func(page *ecs.ListTaskDefinitionFamiliesOutput, lastPage bool) bool {
+		for _, family := range page.Families {
+			families[*family] = true
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing ECS task definition families: %w", err)
+	}
+	return families, nil
+}
+
+func listActiveTaskDefinitionARNs(ecsSvc *ecs.ECS) ([]*string, error) {
+	taskDefinitionARNs := make([]*string, 0)
+	input := &ecs.ListTaskDefinitionsInput{
+		Status: aws.String(ecs.TaskDefinitionStatusActive),
+	}
+	err := ecsSvc.ListTaskDefinitionsPages(input, func(page *ecs.ListTaskDefinitionsOutput, lastPage bool) bool {
+		taskDefinitionARNs = append(taskDefinitionARNs, page.TaskDefinitionArns...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing ECS task definitions: %w", err)
+	}
+	return taskDefinitionARNs, nil
+}
+
+// clusterName returns the name from an ECS cluster ARN like arn:aws:ecs:us-east-1:123456789012:cluster/name
+func clusterName(clusterARN string) string {
+	return clusterARN[strings.LastIndex(clusterARN, "/")+1:]
+}
+
+// describeTasks returns the tasks of the cluster with the given desired status
+func describeTasks(ecsSvc *ecs.ECS, clusterARN *string, desiredStatus string) ([]*ecs.Task, error) {
+	taskARNs := make([]*string, 0)
+	input := &ecs.ListTasksInput{
+		Cluster:       clusterARN,
+		DesiredStatus: aws.String(desiredStatus),
+	}
+	err := ecsSvc.ListTasksPages(input, func(page *ecs.ListTasksOutput, lastPage bool) bool {
+		taskARNs = append(taskARNs, page.TaskArns...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing tasks of %v: %w", *clusterARN, err)
+	}
+
+	// DescribeTasks accepts up to 100 tasks
+	const batchSize = 100
+	tasks := make([]*ecs.Task, 0, len(taskARNs))
+	for start := 0; start < len(taskARNs); start += batchSize {
+		end := start + batchSize
+		if end > len(taskARNs) {
+			end = len(taskARNs)
+		}
+		output, err := ecsSvc.DescribeTasks(&ecs.DescribeTasksInput{
+			Cluster: clusterARN,
+			Tasks:   taskARNs[start:end],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing tasks of %v: %w", *clusterARN, err)
+		}
+		tasks = append(tasks, output.Tasks...)
+	}
+	return tasks, nil
+}
@@ -18,10 +18,15 @@ package aws
 import (
 	"fmt"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 )
 
-// ListUnattachedElasticIPs returns unattached elastic IP addresses
+// amazonIPv4Pool is the public IPv4 pool of Amazon-owned addresses, other pools are BYOIP
+const amazonIPv4Pool = "amazon"
+
+// ListUnattachedElasticIPs returns elastic IP addresses which are not associated, associated with
+// stopped EC2 instances or associated with network interfaces not attached to anything
 func ListUnattachedElasticIPs() ([]Result, error) {
 	sess, err := newSession()
 	if err != nil {
@@ -35,25 +40,84 @@ func ListUnattachedElasticIPs() ([]Result, error) {
 		return nil, err
 	}
 
+	stoppedInstances, err := getStoppedInstanceIDs(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+	orphanedENIs, err := getAvailableENIIDs(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
 	unattachedEIPList := make([]string, 0)
+	stoppedEIPList := make([]string, 0)
+	orphanedEIPList := make([]string, 0)
 	for _, address := range output.Addresses {
-		if address.AssociationId == nil {
-			var name *string
-			for _, tag := range address.Tags {
-				if *tag.Key == "Name" {
-					name = tag.Value
-				}
-			}
-
-			if name != nil {
-				eipWithRegion := fmt.Sprint(*name, ", region: ", *address.NetworkBorderGroup)
-				unattachedEIPList = append(unattachedEIPList, eipWithRegion)
-			} else {
-				eipWithRegion := fmt.Sprint(*address.PublicIp, ", region: ", *address.NetworkBorderGroup)
-				unattachedEIPList = append(unattachedEIPList, eipWithRegion)
-			}
+		switch {
+		case address.AssociationId == nil:
+			unattachedEIPList = append(unattachedEIPList, formatElasticIP(address))
+		case address.InstanceId != nil && stoppedInstances[*address.InstanceId]:
+			eipEntry := fmt.Sprint(formatElasticIP(address), ", instance: ", *address.InstanceId)
+			stoppedEIPList = append(stoppedEIPList, eipEntry)
+		case address.InstanceId == nil && address.NetworkInterfaceId != nil && orphanedENIs[*address.NetworkInterfaceId]:
+			eipEntry := fmt.Sprint(formatElasticIP(address), ", eni: ", *address.NetworkInterfaceId)
+			orphanedEIPList = append(orphanedEIPList, eipEntry)
 		}
 	}
 
-	return []Result{{"Unattached EIP Addresses:", unattachedEIPList}}, nil
+	return []Result{
+		{"Unattached EIP Addresses:", unattachedEIPList},
+		{"EIP Addresses associated with stopped EC2:", stoppedEIPList},
+		{"EIP Addresses associated with unattached ENIs:", orphanedEIPList},
+	}, nil
+}
+
+func formatElasticIP(address *ec2.Address) string {
+	eipEntry := *address.PublicIp
+	if name := getNameTag(address.Tags); name != nil {
+		eipEntry = *name + ", " + eipEntry
+	}
+	eipEntry = fmt.Sprint(eipEntry, ", allocation: ", aws.StringValue(address.AllocationId),
+		", domain: ", aws.StringValue(address.Domain), ", region: ", aws.StringValue(address.NetworkBorderGroup))
+
+	pool := aws.StringValue(address.PublicIpv4Pool)
+	if pool != "" && pool != amazonIPv4Pool {
+		// addresses brought to AWS are not charged for
+		return fmt.Sprint(eipEntry, ", BYOIP pool: ", pool)
+	}
+	return fmt.Sprint(eipEntry, ", cost: ", formatHourlyCost(publicIPv4HourlyPrice))
+}
+
+func getStoppedInstanceIDs(ec2Svc *ec2.EC2) (map[string]bool, error) {
+	filter := &ec2.Filter{
+		Name:   aws.String("instance-state-name"),
+		Values: aws.StringSlice([]string{"stopped"}),
+	}
+	instances, err := describeEC2Instances(nil, []*ec2.Filter{filter}, ec2Svc)
+	if err != nil {
+		return nil, fmt.Errorf("error describing EC2 instances: %w", err)
+	}
+
+	instanceIDs := make(map[string]bool)
+	for _, instance := range instances {
+		instanceIDs[*instance.InstanceId] = true
+	}
+	return instanceIDs, nil
+}
+
+func getAvailableENIIDs(ec2Svc *ec2.EC2) (map[string]bool, error) {
+	filter := &ec2.Filter{
+		Name:   aws.String("status"),
+		Values: aws.StringSlice([]string{"available"}),
+	}
+	networkInterfaces, err := describeNetworkInterfaces([]*ec2.Filter{filter}, ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
+	eniIDs := make(map[string]bool)
+	for _, eni := range networkInterfaces {
+		eniIDs[*eni.NetworkInterfaceId] = true
+	}
+	return eniIDs, nil
 }
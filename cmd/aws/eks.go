@@ -0,0 +1,218 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// idleNodeCPUPercent is the average CPU utilization of a node group below which it is considered idle
+const idleNodeCPUPercent = 5
+
+// managedNodegroupTag is set by EKS on instances and Auto Scaling groups of managed node groups
+const managedNodegroupTag = "eks:nodegroup-name"
+
+// ListIdleEKSResources lists EKS managed node groups at their minimum size with near-zero CPU
+// for the last given number of days, and EKS clusters without any node groups, Fargate profiles
+// or self-managed nodes, or with idle node groups only
+func ListIdleEKSResources(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	eksSvc := eks.New(sess)
+	cwSvc := cloudwatch.New(sess)
+	ec2Svc := ec2.New(sess)
+	autoscalingSvc := autoscaling.New(sess)
+
+	clusterNames := make([]*string, 0)
+	err = eksSvc.ListClustersPages(&eks.ListClustersInput{}, func(page *eks.ListClustersOutput, lastPage bool) bool {
+		clusterNames = append(clusterNames, page.Clusters...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing EKS clusters: %w", err)
+	}
+
+	clusterList := make([]string, 0)
+	nodegroupList := make([]string, 0)
+	for _, clusterName := range clusterNames {
+		nodegroups, err := describeNodegroups(eksSvc, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		fargateProfiles, err := eksSvc.ListFargateProfiles(&eks.ListFargateProfilesInput{ClusterName: clusterName})
+		if err != nil {
+			return nil, fmt.Errorf("error listing Fargate profiles of %v: %w", *clusterName, err)
+		}
+
+		idleNodegroups := 0
+		for _, nodegroup := range nodegroups {
+			cpu, idle, err := isIdleNodegroup(cwSvc, nodegroup, days)
+			if err != nil {
+				return nil, err
+			}
+			if idle {
+				idleNodegroups++
+				nodegroupEntry := fmt.Sprintf("%s/%s, nodes: %d, instance types: %v, cpu: %.1f%%", *clusterName, *nodegroup.NodegroupName,
+					aws.Int64Value(nodegroup.ScalingConfig.DesiredSize), aws.StringValueSlice(nodegroup.InstanceTypes), cpu)
+				nodegroupList = append(nodegroupList, nodegroupEntry)
+			}
+		}
+
+		if idleNodegroups == len(nodegroups) && len(fargateProfiles.FargateProfileNames) == 0 {
+			selfManaged, err := hasSelfManagedNodes(ec2Svc, autoscalingSvc, *clusterName)
+			if err != nil {
+				return nil, err
+			}
+			if selfManaged {
+				continue
+			}
+			clusterEntry := fmt.Sprint(*clusterName, ", node groups: ", len(nodegroups), ", cost: ", formatHourlyCost(eksClusterHourlyPrice))
+			clusterList = append(clusterList, clusterEntry)
+		}
+	}
+
+	return []Result{
+		{"EKS clusters with no workloads:", clusterList},
+		{"Idle EKS node groups:", nodegroupList},
+	}, nil
+}
+
+// isIdleNodegroup reports whether the node group runs at its minimum size and the average CPU
+// utilization of its Auto Scaling groups is below the idle threshold
+func isIdleNodegroup(cwSvc *cloudwatch.CloudWatch, nodegroup *eks.Nodegroup, days int) (float64, bool, error) {
+	scaling := nodegroup.ScalingConfig
+	if scaling == nil || aws.Int64Value(scaling.DesiredSize) > aws.Int64Value(scaling.MinSize) {
+		return 0, false, nil
+	}
+	if aws.Int64Value(scaling.DesiredSize) == 0 {
+		return 0, false, nil
+	}
+	if nodegroup.Resources == nil || len(nodegroup.Resources.AutoScalingGroups) == 0 {
+		return 0, false, nil
+	}
+
+	maxCPU := 0.0
+	for _, asg := range nodegroup.Resources.AutoScalingGroups {
+		cpu, ok, err := averageMetric(cwSvc, "AWS/EC2", "CPUUtilization", newDimension("AutoScalingGroupName", asg.Name), days)
+		if err != nil {
+			return 0, false, fmt.Errorf("error getting %v metrics: %w", *asg.Name, err)
+		}
+		if !ok {
+			return 0, false, nil
+		}
+		if cpu > maxCPU {
+			maxCPU = cpu
+		}
+	}
+	return maxCPU, maxCPU < idleNodeCPUPercent, nil
+}
+
+// hasSelfManagedNodes checks for running instances or Auto Scaling groups tagged with the cluster tag
+// which don't belong to a managed node group
+func hasSelfManagedNodes(ec2Svc *ec2.EC2, autoscalingSvc *autoscaling.AutoScaling, clusterName string) (bool, error) {
+	clusterTag := "kubernetes.io/cluster/" + clusterName
+
+	filters := []*ec2.Filter{
+		{
+			Name:   aws.String("tag-key"),
+			Values: []*string{aws.String(clusterTag)},
+		},
+		{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{"pending", "running"}),
+		},
+	}
+	instances, err := describeEC2Instances(nil, filters, ec2Svc)
+	if err != nil {
+		return false, fmt.Errorf("error describing EC2 instances of %v: %w", clusterName, err)
+	}
+	for _, instance := range instances {
+		if !hasTag(instance.Tags, managedNodegroupTag) {
+			return true, nil
+		}
+	}
+
+	selfManaged := false
+	input := &autoscaling.DescribeAutoScalingGroupsInput{
+		Filters: []*autoscaling.Filter{{
+			Name:   aws.String("tag-key"),
+			Values: []*string{aws.String(clusterTag)},
+		}},
+	}
+	err = autoscalingSvc.DescribeAutoScalingGroupsPages(input, func(page *autoscaling.DescribeAutoScalingGroupsOutput, lastPage bool) bool {
+		for _, group := range page.AutoScalingGroups {
+			managed := false
+			for _, tag := range group.Tags {
+				if aws.StringValue(tag.Key) == managedNodegroupTag {
+					managed = true
+				}
+			}
+			if !managed {
+				selfManaged = true
+				return false
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return false, fmt.Errorf("error describing Auto Scaling groups of %v: %w", clusterName, err)
+	}
+	return selfManaged, nil
+}
+
+func hasTag(tags []*ec2.Tag, key string) bool {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func describeNodegroups(eksSvc *eks.EKS, clusterName *string) ([]*eks.Nodegroup, error) {
+	nodegroupNames := make([]*string, 0)
+	input := &eks.ListNodegroupsInput{
+		ClusterName: clusterName,
+	}
+	err := eksSvc.ListNodegroupsPages(input, func(page *eks.ListNodegroupsOutput, lastPage bool) bool {
+		nodegroupNames = append(nodegroupNames, page.Nodegroups...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing node groups of %v: %w", *clusterName, err)
+	}
+
+	nodegroups := make([]*eks.Nodegroup, 0, len(nodegroupNames))
+	for _, nodegroupName := range nodegroupNames {
+		output, err := eksSvc.DescribeNodegroup(&eks.DescribeNodegroupInput{
+			ClusterName:   clusterName,
+			NodegroupName: nodegroupName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error describing node group %v: %w", *nodegroupName, err)
+		}
+		nodegroups = append(nodegroups, output.Nodegroup)
+	}
+	return nodegroups, nil
+}
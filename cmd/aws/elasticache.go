@@ -0,0 +1,135 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+)
+
+// idleCacheCommandsPerDay is the number of get and set commands per day a cluster may serve and still count as idle,
+// connections alone don't tell as replication and monitoring keep them open
+const idleCacheCommandsPerDay = 100
+
+// ListIdleCacheClusters lists ElastiCache clusters with close to no get and set commands
+// for the last given number of days
+func ListIdleCacheClusters(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	elastiCacheSvc := elasticache.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	clusters, err := describeCacheClusters(elastiCacheSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	idleList := make([]string, 0)
+	for _, cluster := range clusters {
+		dimensions := newDimension("CacheClusterId", cluster.CacheClusterId)
+		// Memcached reports get and set commands as CmdGet and CmdSet
+		commandMetrics := []string{"GetTypeCmds", "SetTypeCmds"}
+		if aws.StringValue(cluster.Engine) == "memcached" {
+			commandMetrics = []string{"CmdGet", "CmdSet"}
+		}
+		commands := 0.0
+		for _, metricName := range commandMetrics {
+			sum, err := sumMetric(cwSvc, "AWS/ElastiCache", metricName, dimensions, days)
+			if err != nil {
+				return nil, fmt.Errorf("error getting %v metrics: %w", *cluster.CacheClusterId, err)
+			}
+			commands += sum
+		}
+		if commands > float64(idleCacheCommandsPerDay*days) {
+			continue
+		}
+
+		connections, err := maxMetric(cwSvc, "AWS/ElastiCache", "CurrConnections", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *cluster.CacheClusterId, err)
+		}
+		clusterEntry := fmt.Sprint(*cluster.CacheClusterId, ", engine: ", aws.StringValue(cluster.Engine),
+			", node type: ", aws.StringValue(cluster.CacheNodeType), ", nodes: ", aws.Int64Value(cluster.NumCacheNodes),
+			", get and set commands: ", commands, ", max connections: ", connections)
+		if cluster.ReplicationGroupId != nil {
+			clusterEntry = clusterEntry + ", replication group: " + *cluster.ReplicationGroupId
+		}
+		idleList = append(idleList, clusterEntry)
+	}
+
+	return []Result{{"Idle ElastiCache clusters:", idleList}}, nil
+}
+
+// listOrphanedCacheSnapshots returns manual (user created) ElastiCache snapshots of clusters and replication groups
+// which no longer exist
+func listOrphanedCacheSnapshots(elastiCacheSvc *elasticache.ElastiCache) ([]string, error) {
+	clusters, err := describeCacheClusters(elastiCacheSvc)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	for _, cluster := range clusters {
+		existing[*cluster.CacheClusterId] = true
+		if cluster.ReplicationGroupId != nil {
+			existing[*cluster.ReplicationGroupId] = true
+		}
+	}
+
+	snapshotList := make([]string, 0)
+	input := &elasticache.DescribeSnapshotsInput{
+		SnapshotSource: aws.String("user"),
+	}
+	err = elastiCacheSvc.DescribeSnapshotsPages(input, func(page *elasticache.DescribeSnapshotsOutput, lastPage bool) bool {
+		for _, snapshot := range page.Snapshots {
+			source := aws.StringValue(snapshot.ReplicationGroupId)
+			if source == "" {
+				source = aws.StringValue(snapshot.CacheClusterId)
+			}
+			if existing[source] {
+				continue
+			}
+			snapshotEntry := fmt.Sprint(*snapshot.SnapshotName, ", source: ", source, ", engine: ", aws.StringValue(snapshot.Engine))
+			if len(snapshot.NodeSnapshots) > 0 && snapshot.NodeSnapshots[0].SnapshotCreateTime != nil {
+				snapshotEntry = snapshotEntry + ", created: " + snapshot.NodeSnapshots[0].SnapshotCreateTime.Format(time.RFC3339)
+			}
+			snapshotList = append(snapshotList, snapshotEntry)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing ElastiCache snapshots: %w", err)
+	}
+	return snapshotList, nil
+}
+
+func describeCacheClusters(elastiCacheSvc *elasticache.ElastiCache) ([]*elasticache.CacheCluster, error) {
+	clusters := make([]*elasticache.CacheCluster, 0)
+	input := &elasticache.DescribeCacheClustersInput{}
+	err := elastiCacheSvc.DescribeCacheClustersPages(input, func(page *elasticache.DescribeCacheClustersOutput, lastPage bool) bool {
+		clusters = append(clusters, page.CacheClusters...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing ElastiCache clusters: %w", err)
+	}
+	return clusters, nil
+}
@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 )
@@ -74,12 +76,93 @@ func targetGroupsNotInUse(elbSvc *elbv2.ELBV2, targetGroups []*elbv2.TargetGroup
 	return true, nil
 }
 
+// Traffic over the whole lookback window below which a load balancer is considered idle
+const (
+	idleELBRequestThreshold = 100
+	idleELBBytesThreshold   = 10 * 1024 * 1024
+	// idleELBActiveFlowThreshold is the highest number of concurrent flows of an idle NLB or GWLB
+	idleELBActiveFlowThreshold = 10
+)
+
+// ListIdleELBs returns Application, Network and Gateway Load Balancers with no meaningful
+// traffic for the last given number of days
+func ListIdleELBs(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	svc := elbv2.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	elbList, err := describeAllELBs(svc)
+	if err != nil {
+		return nil, err
+	}
+
+	idleELBList := make([]string, 0)
+	for _, elb := range elbList {
+		namespace, countMetrics, gaugeMetric, hourlyPrice := elbV2Metrics(*elb.Type)
+		// the LoadBalancer dimension is the ARN suffix, e.g. app/my-alb/50dc6c495c0c9188
+		arnSuffix := strings.SplitN(*elb.LoadBalancerArn, ":loadbalancer/", 2)[1]
+		dimensions := newDimension("LoadBalancer", aws.String(arnSuffix))
+
+		idle, err := isIdleLoadBalancer(cwSvc, namespace, countMetrics, "ProcessedBytes", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *elb.LoadBalancerName, err)
+		}
+		if idle && gaugeMetric != "" {
+			// gauges such as ActiveFlowCount can't be summed up, their maximum is compared instead
+			activeFlows, err := maxMetric(cwSvc, namespace, gaugeMetric, dimensions, days)
+			if err != nil {
+				return nil, fmt.Errorf("error getting %v metrics: %w", *elb.LoadBalancerName, err)
+			}
+			idle = activeFlows < idleELBActiveFlowThreshold
+		}
+		if idle {
+			elbEntry := fmt.Sprint(*elb.LoadBalancerName, ", type: ", *elb.Type, ", cost: ", formatHourlyCost(hourlyPrice))
+			idleELBList = append(idleELBList, elbEntry)
+		}
+	}
+	return []Result{{"Idle ELBv2:", idleELBList}}, nil
+}
+
+// elbV2Metrics returns the namespace, the count metrics which are summed up, the gauge metric
+// whose maximum is used and the hourly price of the load balancer type
+func elbV2Metrics(elbType string) (string, []string, string, float64) {
+	switch elbType {
+	case elbv2.LoadBalancerTypeEnumNetwork:
+		return "AWS/NetworkELB", []string{"NewFlowCount"}, "ActiveFlowCount", nlbHourlyPrice
+	case elbv2.LoadBalancerTypeEnumGateway:
+		return "AWS/GatewayELB", []string{"NewFlowCount"}, "ActiveFlowCount", gwlbHourlyPrice
+	default:
+		return "AWS/ApplicationELB", []string{"RequestCount"}, "", albHourlyPrice
+	}
+}
+
+// isIdleLoadBalancer reports whether all count metrics and the bytes metric are below the idle thresholds
+func isIdleLoadBalancer(cwSvc *cloudwatch.CloudWatch, namespace string, countMetrics []string, bytesMetric string, dimensions []*cloudwatch.Dimension, days int) (bool, error) {
+	for _, metricName := range countMetrics {
+		count, err := sumMetric(cwSvc, namespace, metricName, dimensions, days)
+		if err != nil {
+			return false, err
+		}
+		if count >= idleELBRequestThreshold {
+			return false, nil
+		}
+	}
+	processedBytes, err := sumMetric(cwSvc, namespace, bytesMetric, dimensions, days)
+	if err != nil {
+		return false, err
+	}
+	return processedBytes < idleELBBytesThreshold, nil
+}
+
 func describeAllELBs(elbV2Svc *elbv2.ELBV2) ([]*elbv2.LoadBalancer, error) {
 	elbList := make([]*elbv2.LoadBalancer, 0)
 	input := &elbv2.DescribeLoadBalancersInput{}
 	err := elbV2Svc.DescribeLoadBalancersPages(input, func(page *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
 		elbList = append(elbList, page.LoadBalancers...)
-		return lastPage
+		return !lastPage
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error describing ELBs: %w", err)
@@ -101,16 +184,101 @@ func ListUnattachedClassicLBs() ([]Result, error) {
 		return nil, err
 	}
 
+	region := sessionRegion(sess)
+	accountID, err := getAccountID(sess)
+	if err != nil {
+		return nil, err
+	}
+
 	unattachedELBList := make([]string, 0)
+	outOfServiceELBList := make([]string, 0)
+	migrationELBList := make([]string, 0)
 	for _, elb := range elbList {
+		arn := classicLBArn(region, accountID, *elb.LoadBalancerName)
+		elbWithRegion := fmt.Sprint(*elb.LoadBalancerName, ", region: ", region, ", arn: ", arn)
 		if len(elb.Instances) == 0 {
-			region := extractClassicLBRegion(elb)
-			elbWithRegion := fmt.Sprint(*elb.LoadBalancerName, ", region: ", region)
 			unattachedELBList = append(unattachedELBList, elbWithRegion)
+			continue
+		}
+
+		inService, err := hasInServiceInstances(elbSvc, elb.LoadBalancerName)
+		if err != nil {
+			return nil, err
+		}
+		if !inService {
+			outOfServiceELBList = append(outOfServiceELBList, elbWithRegion)
+			continue
+		}
+
+		if elb.VPCId != nil {
+			migrationEntry := fmt.Sprint(elbWithRegion, ", migrate to: ", classicLBMigrationTarget(elb))
+			migrationELBList = append(migrationELBList, migrationEntry)
+		}
+	}
+
+	return []Result{
+		{"Unattached ELBv1:", unattachedELBList},
+		{"ELBv1 with no InService instances:", outOfServiceELBList},
+		{"ELBv1 eligible for migration to ELBv2:", migrationELBList},
+	}, nil
+}
+
+func hasInServiceInstances(elbSvc *elb.ELB, name *string) (bool, error) {
+	input := &elb.DescribeInstanceHealthInput{
+		LoadBalancerName: name,
+	}
+	output, err := elbSvc.DescribeInstanceHealth(input)
+	if err != nil {
+		return false, fmt.Errorf("error describing instance health for %v: %w", *name, err)
+	}
+	for _, state := range output.InstanceStates {
+		if aws.StringValue(state.State) == "InService" {
+			return true, nil
 		}
 	}
+	return false, nil
+}
 
-	return []Result{{"Unattached ELBv1:", unattachedELBList}}, nil
+// classicLBMigrationTarget returns ALB for classic ELBs with only HTTP and HTTPS listeners, NLB otherwise
+func classicLBMigrationTarget(elb *elb.LoadBalancerDescription) string {
+	for _, listenerDescription := range elb.ListenerDescriptions {
+		switch strings.ToUpper(aws.StringValue(listenerDescription.Listener.Protocol)) {
+		case "HTTP", "HTTPS":
+		default:
+			return "NLB"
+		}
+	}
+	return "ALB"
+}
+
+// ListIdleClassicLBs returns Classic Load Balancers with no meaningful traffic for the last given number of days
+func ListIdleClassicLBs(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	elbSvc := elb.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	elbList, err := describeAllClassicLBs(elbSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	idleELBList := make([]string, 0)
+	for _, elb := range elbList {
+		dimensions := newDimension("LoadBalancerName", elb.LoadBalancerName)
+		idle, err := isIdleLoadBalancer(cwSvc, "AWS/ELB", []string{"RequestCount"}, "EstimatedProcessedBytes", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *elb.LoadBalancerName, err)
+		}
+		if idle {
+			elbEntry := fmt.Sprint(*elb.LoadBalancerName, ", cost: ", formatHourlyCost(classicELBHourlyPrice))
+			idleELBList = append(idleELBList, elbEntry)
+		}
+	}
+
+	return []Result{{"Idle ELBv1:", idleELBList}}, nil
 }
 
 func describeAllClassicLBs(elbSvc *elb.ELB) ([]*elb.LoadBalancerDescription, error) {
@@ -118,7 +286,7 @@ func describeAllClassicLBs(elbSvc *elb.ELB) ([]*elb.LoadBalancerDescription, err
 	input := &elb.DescribeLoadBalancersInput{}
 	err := elbSvc.DescribeLoadBalancersPages(input, func(page *elb.DescribeLoadBalancersOutput, lastPage bool) bool {
 		elbList = append(elbList, page.LoadBalancerDescriptions...)
-		return lastPage
+		return !lastPage
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error describing classic ELBs: %w", err)
@@ -127,16 +295,7 @@ func describeAllClassicLBs(elbSvc *elb.ELB) ([]*elb.LoadBalancerDescription, err
 	return elbList, nil
 }
 
-func extractClassicLBRegion(elb *elb.LoadBalancerDescription) string {
-	var region string
-	if elb.AvailabilityZones != nil && len(elb.AvailabilityZones) > 0 {
-		az := *elb.AvailabilityZones[0]
-		region = az[:len(az)-1]
-	}
-	if elb.CanonicalHostedZoneName != nil {
-		region = strings.Split(*elb.CanonicalHostedZoneName, ".")[1]
-	} else if elb.DNSName != nil {
-		region = strings.Split(*elb.DNSName, ".")[1]
-	}
-	return region
+// classicLBArn builds the ARN of a classic ELB, which is not returned by the ELB API
+func classicLBArn(region, accountID, name string) string {
+	return fmt.Sprintf("arn:%s:elasticloadbalancing:%s:%s:loadbalancer/%s", partitionForRegion(region), region, accountID, name)
 }
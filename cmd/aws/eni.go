@@ -0,0 +1,107 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// ListUnattachedENIs returns network interfaces with available status which can be deleted
+func ListUnattachedENIs() ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ec2Svc := ec2.New(sess)
+
+	filter := &ec2.Filter{
+		Name:   aws.String("status"),
+		Values: aws.StringSlice([]string{"available"}),
+	}
+	networkInterfaces, err := describeNetworkInterfaces([]*ec2.Filter{filter}, ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
+	eniList := make([]string, 0)
+	for _, eni := range networkInterfaces {
+		if isManagedENI(eni) {
+			continue
+		}
+		eniList = append(eniList, formatENI(eni))
+	}
+
+	return []Result{{"Unattached ENIs:", eniList}}, nil
+}
+
+// isManagedENI reports whether the network interface is managed by an AWS service
+// and so cannot be deleted by the user
+func isManagedENI(eni *ec2.NetworkInterface) bool {
+	if eni.RequesterManaged != nil && *eni.RequesterManaged {
+		return true
+	}
+	switch aws.StringValue(eni.InterfaceType) {
+	case ec2.NetworkInterfaceTypeInterface, ec2.NetworkInterfaceTypeEfa, "":
+		return false
+	default:
+		return true
+	}
+}
+
+func formatENI(eni *ec2.NetworkInterface) string {
+	groupIDs := make([]string, 0, len(eni.Groups))
+	for _, group := range eni.Groups {
+		groupIDs = append(groupIDs, *group.GroupId)
+	}
+
+	eniEntry := fmt.Sprint(*eni.NetworkInterfaceId, ", vpc: ", *eni.VpcId, ", subnet: ", *eni.SubnetId,
+		", security groups: [", strings.Join(groupIDs, " "), "]")
+	if name := getNameTag(eni.TagSet); name != nil {
+		eniEntry = *name + ", " + eniEntry
+	}
+	if description := aws.StringValue(eni.Description); description != "" {
+		eniEntry = eniEntry + ", description: " + description
+	}
+	if eni.RequesterId != nil {
+		eniEntry = eniEntry + ", requester: " + *eni.RequesterId
+	}
+	for _, tag := range eni.TagSet {
+		if *tag.Key == "aws:cloudformation:stack-name" {
+			eniEntry = eniEntry + ", stack: " + *tag.Value
+		}
+	}
+	return eniEntry
+}
+
+func describeNetworkInterfaces(filters []*ec2.Filter, ec2Svc *ec2.EC2) ([]*ec2.NetworkInterface, error) {
+	networkInterfaces := make([]*ec2.NetworkInterface, 0)
+	input := &ec2.DescribeNetworkInterfacesInput{
+		Filters: filters,
+	}
+	err := ec2Svc.DescribeNetworkInterfacesPages(input, func(page *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+		networkInterfaces = append(networkInterfaces, page.NetworkInterfaces...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing network interfaces: %w", err)
+	}
+
+	return networkInterfaces, nil
+}
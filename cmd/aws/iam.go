@@ -0,0 +1,331 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+// credentialReportPollInterval is the delay between checks of the credential report generation
+// and credentialReportTimeout is how long to wait for the report before giving up,
+// the same bounds apply to service last accessed jobs
+const (
+	credentialReportPollInterval = 2 * time.Second
+	credentialReportTimeout      = 2 * time.Minute
+)
+
+// credentialReportUser is a row of the IAM credential report
+type credentialReportUser map[string]string
+
+// ListUnusedIAMResources returns IAM users, access keys and roles with no activity for the last given number of days,
+// services granted to roles but not accessed, customer managed policies not attached or not used
+// and instance profiles without roles
+func ListUnusedIAMResources(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	iamSvc := iam.New(sess)
+
+	users, err := getCredentialReport(iamSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := time.Now().AddDate(0, 0, -days)
+	userList := make([]string, 0)
+	keyList := make([]string, 0)
+	for _, user := range users {
+		// the root account has its own row, unused root credentials are expected
+		if user["user"] == "<root_account>" {
+			continue
+		}
+		created := user.time("user_creation_time")
+		lastActivity := user.time("password_last_used")
+
+		for _, key := range []string{"access_key_1", "access_key_2"} {
+			if user[key+"_active"] != "true" {
+				continue
+			}
+			lastUsed := user.time(key + "_last_used_date")
+			if lastUsed.After(lastActivity) {
+				lastActivity = lastUsed
+			}
+			rotated := user.time(key + "_last_rotated")
+			switch {
+			case lastUsed.IsZero() && rotated.Before(threshold):
+				keyList = append(keyList, fmt.Sprint(user["user"], ", ", key, ", created: ", rotated.Format(time.RFC3339), ", never used"))
+			case !lastUsed.IsZero() && lastUsed.Before(threshold):
+				keyList = append(keyList, fmt.Sprint(user["user"], ", ", key, ", last used: ", lastUsed.Format(time.RFC3339),
+					", service: ", user[key+"_last_used_service"]))
+			}
+		}
+
+		if created.Before(threshold) && lastActivity.Before(threshold) {
+			userEntry := fmt.Sprint(user["user"], ", created: ", created.Format(time.RFC3339), ", console: ", user["password_enabled"])
+			if lastActivity.IsZero() {
+				userEntry = userEntry + ", never active"
+			} else {
+				userEntry = userEntry + ", last active: " + lastActivity.Format(time.RFC3339)
+			}
+			userList = append(userList, userEntry)
+		}
+	}
+
+	roleList, usedRoles, err := listUnusedRoles(iamSvc, threshold)
+	if err != nil {
+		return nil, err
+	}
+	policyList, attachedPolicies, err := listUnattachedPolicies(iamSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	// service last accessed data covers the permissions of roles still in use and of attached policies
+	arns := make([]string, 0, len(usedRoles)+len(attachedPolicies))
+	for _, role := range usedRoles {
+		arns = append(arns, *role.Arn)
+	}
+	for _, policy := range attachedPolicies {
+		arns = append(arns, *policy.Arn)
+	}
+	lastAccessed, err := getServiceLastAccessed(iamSvc, arns)
+	if err != nil {
+		return nil, err
+	}
+
+	roleServiceList := make([]string, 0)
+	for _, role := range usedRoles {
+		services := lastAccessed[*role.Arn]
+		if notAccessed := servicesNotAccessed(services, threshold); len(notAccessed) > 0 {
+			roleServiceList = append(roleServiceList, fmt.Sprint(*role.RoleName, ", not accessed: ",
+				len(notAccessed), " of ", len(services), " services [", strings.Join(notAccessed, " "), "]"))
+		}
+	}
+	unusedPolicyList := make([]string, 0)
+	for _, policy := range attachedPolicies {
+		services := lastAccessed[*policy.Arn]
+		if len(services) > 0 && len(servicesNotAccessed(services, threshold)) == len(services) {
+			unusedPolicyList = append(unusedPolicyList, fmt.Sprint(*policy.PolicyName, ", arn: ", *policy.Arn,
+				", attachments: ", aws.Int64Value(policy.AttachmentCount), ", services: ", len(services)))
+		}
+	}
+	profileList, err := listEmptyInstanceProfiles(iamSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Result{
+		{"Users with no console or access key activity:", userList},
+		{"Active access keys not used recently or never used:", keyList},
+		{"Roles not assumed recently:", roleList},
+		{"Roles with services granted but not accessed recently:", roleServiceList},
+		{"Customer managed policies not attached:", policyList},
+		{"Customer managed policies attached but with no service accessed recently:", unusedPolicyList},
+		{"Instance profiles without roles:", profileList},
+	}, nil
+}
+
+// time parses a credential report timestamp, returning zero time for N/A and no_information values
+func (u credentialReportUser) time(column string) time.Time {
+	t, err := time.Parse(time.RFC3339, u[column])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// listUnusedRoles returns roles not assumed since the threshold and the roles assumed after it
+func listUnusedRoles(iamSvc *iam.IAM, threshold time.Time) ([]string, []*iam.Role, error) {
+	roles := make([]*iam.Role, 0)
+	err := iamSvc.ListRolesPages(&iam.ListRolesInput{}, func(page *iam.ListRolesOutput, lastPage bool) bool {
+		roles = append(roles, page.Roles...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing roles: %w", err)
+	}
+
+	roleList := make([]string, 0)
+	usedRoles := make([]*iam.Role, 0)
+	for _, role := range roles {
+		// service-linked roles are managed by AWS services and can't be deleted directly
+		if strings.HasPrefix(aws.StringValue(role.Path), "/aws-service-role/") || role.CreateDate.After(threshold) {
+			continue
+		}
+		// RoleLastUsed is only returned by GetRole
+		output, err := iamSvc.GetRole(&iam.GetRoleInput{RoleName: role.RoleName})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting role %v: %w", *role.RoleName, err)
+		}
+		lastUsed := output.Role.RoleLastUsed
+		if lastUsed == nil || lastUsed.LastUsedDate == nil {
+			roleList = append(roleList, fmt.Sprint(*role.RoleName, ", created: ", role.CreateDate.Format(time.RFC3339), ", never used"))
+		} else if lastUsed.LastUsedDate.Before(threshold) {
+			roleList = append(roleList, fmt.Sprint(*role.RoleName, ", last used: ", lastUsed.LastUsedDate.Format(time.RFC3339),
+				", region: ", aws.StringValue(lastUsed.Region)))
+		} else {
+			usedRoles = append(usedRoles, role)
+		}
+	}
+	return roleList, usedRoles, nil
+}
+
+// listUnattachedPolicies returns customer managed policies not attached to anything and the attached ones
+func listUnattachedPolicies(iamSvc *iam.IAM) ([]string, []*iam.Policy, error) {
+	policyList := make([]string, 0)
+	attachedPolicies := make([]*iam.Policy, 0)
+	input := &iam.ListPoliciesInput{
+		Scope: aws.String(iam.PolicyScopeTypeLocal),
+	}
+	err := iamSvc.ListPoliciesPages(input, func(page *iam.ListPoliciesOutput, lastPage bool) bool {
+		for _, policy := range page.Policies {
+			if aws.Int64Value(policy.AttachmentCount) == 0 {
+				policyList = append(policyList, fmt.Sprint(*policy.PolicyName, ", arn: ", *policy.Arn))
+			} else {
+				attachedPolicies = append(attachedPolicies, policy)
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing policies: %w", err)
+	}
+	return policyList, attachedPolicies, nil
+}
+
+func listEmptyInstanceProfiles(iamSvc *iam.IAM) ([]string, error) {
+	profileList := make([]string, 0)
+	err := iamSvc.ListInstanceProfilesPages(&iam.ListInstanceProfilesInput{}, func(page *iam.ListInstanceProfilesOutput, lastPage bool) bool {
+		for _, profile := range page.InstanceProfiles {
+			if len(profile.Roles) == 0 {
+				profileList = append(profileList, fmt.Sprint(*profile.InstanceProfileName, ", arn: ", *profile.Arn))
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing instance profiles: %w", err)
+	}
+	return profileList, nil
+}
+
+// getServiceLastAccessed starts a service last accessed job for every given role or policy ARN,
+// then waits for the jobs and returns the services each of them grants keyed by ARN
+func getServiceLastAccessed(iamSvc *iam.IAM, arns []string) (map[string][]*iam.ServiceLastAccessed, error) {
+	jobIDs := make(map[string]string, len(arns))
+	for _, arn := range arns {
+		output, err := iamSvc.GenerateServiceLastAccessedDetails(&iam.GenerateServiceLastAccessedDetailsInput{
+			Arn:         aws.String(arn),
+			Granularity: aws.String(iam.AccessAdvisorUsageGranularityTypeServiceLevel),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error generating service last accessed details for %v: %w", arn, err)
+		}
+		jobIDs[arn] = *output.JobId
+	}
+
+	deadline := time.Now().Add(credentialReportTimeout)
+	services := make(map[string][]*iam.ServiceLastAccessed, len(arns))
+	for arn, jobID := range jobIDs {
+		input := &iam.GetServiceLastAccessedDetailsInput{JobId: aws.String(jobID)}
+		for {
+			output, err := iamSvc.GetServiceLastAccessedDetails(input)
+			if err != nil {
+				return nil, fmt.Errorf("error getting service last accessed details for %v: %w", arn, err)
+			}
+			switch aws.StringValue(output.JobStatus) {
+			case iam.JobStatusTypeInProgress:
+				if time.Now().After(deadline) {
+					return nil, fmt.Errorf("service last accessed details not generated within %v", credentialReportTimeout)
+				}
+				time.Sleep(credentialReportPollInterval)
+				continue
+			case iam.JobStatusTypeFailed:
+				message := ""
+				if output.Error != nil {
+					message = aws.StringValue(output.Error.Message)
+				}
+				return nil, fmt.Errorf("error generating service last accessed details for %v: %v", arn, message)
+			}
+			services[arn] = append(services[arn], output.ServicesLastAccessed...)
+			if !aws.BoolValue(output.IsTruncated) {
+				break
+			}
+			input.Marker = output.Marker
+		}
+	}
+	return services, nil
+}
+
+// servicesNotAccessed returns namespaces of the services never accessed or last accessed before the threshold
+func servicesNotAccessed(services []*iam.ServiceLastAccessed, threshold time.Time) []string {
+	namespaces := make([]string, 0)
+	for _, service := range services {
+		if service.LastAuthenticated == nil || service.LastAuthenticated.Before(threshold) {
+			namespaces = append(namespaces, *service.ServiceNamespace)
+		}
+	}
+	return namespaces
+}
+
+// getCredentialReport generates the IAM credential report and parses it into rows keyed by column name
+func getCredentialReport(iamSvc *iam.IAM) ([]credentialReportUser, error) {
+	deadline := time.Now().Add(credentialReportTimeout)
+	for {
+		output, err := iamSvc.GenerateCredentialReport(&iam.GenerateCredentialReportInput{})
+		if err != nil {
+			return nil, fmt.Errorf("error generating credential report: %w", err)
+		}
+		if aws.StringValue(output.State) == iam.ReportStateTypeComplete {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("credential report not generated within %v", credentialReportTimeout)
+		}
+		time.Sleep(credentialReportPollInterval)
+	}
+
+	output, err := iamSvc.GetCredentialReport(&iam.GetCredentialReportInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting credential report: %w", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(output.Content)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing credential report: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	users := make([]credentialReportUser, 0, len(records)-1)
+	for _, record := range records[1:] {
+		user := make(credentialReportUser, len(header))
+		for i, column := range header {
+			user[column] = record[i]
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
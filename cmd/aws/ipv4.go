@@ -0,0 +1,172 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// IpOwnerId values of public IPv4 associations, addresses of other owners carry their account ID
+const (
+	amazonIPOwner    = "amazon"
+	amazonELBIPOwner = "amazon-elb"
+)
+
+// ipv4Inventory holds the public IPv4 addresses of a region and their monthly cost,
+// BYOIP addresses are listed but not charged
+type ipv4Inventory struct {
+	ipList            []string
+	wastedIPList      []string
+	monthlyCost       float64
+	wastedMonthlyCost float64
+}
+
+// ReportPublicIPv4Addresses lists public IPv4 addresses in use with their monthly cost per region,
+// highlighting the ones on stopped instances, unattached network interfaces or unassociated EIPs.
+// Idle NAT gateways and load balancers are reported by the idle command instead
+func ReportPublicIPv4Addresses() ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	accountID, err := getAccountID(sess)
+	if err != nil {
+		return nil, err
+	}
+	regions, err := describeRegions(ec2.New(sess))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(regions)+1)
+	wastedIPList := make([]string, 0)
+	wastedMonthlyCost := 0.0
+	for _, region := range regions {
+		inventory, err := listPublicIPv4Addresses(ec2.New(sess.Copy(aws.NewConfig().WithRegion(region))))
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", region, err)
+		}
+		if len(inventory.ipList) == 0 {
+			continue
+		}
+		label := fmt.Sprintf("Public IPv4 addresses in account %s, region %s (%d addresses, $%.2f/month):",
+			accountID, region, len(inventory.ipList), inventory.monthlyCost)
+		results = append(results, Result{label, inventory.ipList})
+		for _, ipEntry := range inventory.wastedIPList {
+			wastedIPList = append(wastedIPList, ipEntry+", region: "+region)
+		}
+		wastedMonthlyCost += inventory.wastedMonthlyCost
+	}
+
+	wastedLabel := fmt.Sprintf("Public IPv4 addresses on stopped instances, unattached ENIs or unassociated EIPs ($%.2f/month):",
+		wastedMonthlyCost)
+	return append(results, Result{wastedLabel, wastedIPList}), nil
+}
+
+// listPublicIPv4Addresses returns public IPv4 addresses in the region of the client
+// and the ones of them on stopped or unattached resources
+func listPublicIPv4Addresses(ec2Svc *ec2.EC2) (*ipv4Inventory, error) {
+	stoppedInstances, err := getStoppedInstanceIDs(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+	networkInterfaces, err := describeNetworkInterfaces(nil, ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+	output, err := ec2Svc.DescribeAddresses(&ec2.DescribeAddressesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error describing addresses: %w", err)
+	}
+	byoipAddresses := make(map[string]bool)
+	for _, address := range output.Addresses {
+		if pool := aws.StringValue(address.PublicIpv4Pool); pool != "" && pool != amazonIPv4Pool {
+			byoipAddresses[*address.PublicIp] = true
+		}
+	}
+
+	inventory := &ipv4Inventory{
+		ipList:       make([]string, 0),
+		wastedIPList: make([]string, 0),
+	}
+	add := func(ipEntry, publicIP, wasteReason string) {
+		cost := publicIPv4HourlyPrice * hoursPerMonth
+		if byoipAddresses[publicIP] {
+			ipEntry = ipEntry + ", byoip (not charged)"
+			cost = 0
+		}
+		inventory.ipList = append(inventory.ipList, ipEntry)
+		inventory.monthlyCost += cost
+		if wasteReason != "" {
+			inventory.wastedIPList = append(inventory.wastedIPList, ipEntry+", "+wasteReason)
+			inventory.wastedMonthlyCost += cost
+		}
+	}
+
+	for _, eni := range networkInterfaces {
+		for _, privateIP := range eni.PrivateIpAddresses {
+			association := privateIP.Association
+			if association == nil || association.PublicIp == nil {
+				continue
+			}
+			ipEntry := fmt.Sprint(*association.PublicIp, ", type: ", publicIPSource(eni, association), ", eni: ", *eni.NetworkInterfaceId)
+			if eni.Attachment != nil && eni.Attachment.InstanceId != nil {
+				ipEntry = ipEntry + ", instance: " + *eni.Attachment.InstanceId
+			}
+
+			wasteReason := ""
+			switch {
+			case aws.StringValue(eni.Status) == ec2.NetworkInterfaceStatusAvailable:
+				wasteReason = "unattached ENI"
+			case eni.Attachment != nil && stoppedInstances[aws.StringValue(eni.Attachment.InstanceId)]:
+				wasteReason = "stopped instance"
+			}
+			add(ipEntry, *association.PublicIp, wasteReason)
+		}
+	}
+
+	// unassociated elastic IPs are not on any network interface but are charged as well
+	for _, address := range output.Addresses {
+		if address.AssociationId == nil {
+			add(fmt.Sprint(*address.PublicIp, ", type: eip"), *address.PublicIp, "not associated")
+		}
+	}
+
+	return inventory, nil
+}
+
+func publicIPSource(eni *ec2.NetworkInterface, association *ec2.NetworkInterfaceAssociation) string {
+	interfaceType := aws.StringValue(eni.InterfaceType)
+	description := aws.StringValue(eni.Description)
+	ipOwner := aws.StringValue(association.IpOwnerId)
+	switch {
+	case interfaceType == ec2.NetworkInterfaceTypeNatGateway || interfaceType == "nat_gateway":
+		return "nat"
+	case interfaceType == "network_load_balancer" || ipOwner == amazonELBIPOwner || strings.HasPrefix(description, "ELB "):
+		return "elb"
+	case association.AllocationId != nil:
+		return "eip"
+	case ipOwner == amazonIPOwner:
+		return "auto-assigned"
+	default:
+		return "other"
+	}
+}
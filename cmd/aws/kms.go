@@ -0,0 +1,247 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// cloudTrailLookupInterval keeps LookupEvents calls within the CloudTrail limit of 2 requests per second
+const cloudTrailLookupInterval = 500 * time.Millisecond
+
+// kmsCryptoEvents are the CloudTrail event names of cryptographic operations, management events
+// such as DescribeKey are also recorded for security scanners and don't mean the key is in use
+var kmsCryptoEvents = map[string]bool{
+	"Encrypt":                             true,
+	"Decrypt":                             true,
+	"ReEncrypt":                           true,
+	"GenerateDataKey":                     true,
+	"GenerateDataKeyWithoutPlaintext":     true,
+	"GenerateDataKeyPair":                 true,
+	"GenerateDataKeyPairWithoutPlaintext": true,
+	"Sign":                                true,
+	"Verify":                              true,
+	"GenerateMac":                         true,
+	"VerifyMac":                           true,
+}
+
+// ListUnusedKMSKeys returns enabled customer managed KMS keys with no cryptographic operations in CloudTrail
+// for the last given number of days which are not referenced by EBS volumes, RDS databases or S3 bucket encryption
+func ListUnusedKMSKeys(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	kmsSvc := kms.New(sess)
+	ctSvc := cloudtrail.New(sess)
+
+	keys, err := describeCustomerKeys(kmsSvc)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := getKeyAliases(kmsSvc)
+	if err != nil {
+		return nil, err
+	}
+	referencedKeys, err := getReferencedKeyIDs(sess, aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	throttle := time.NewTicker(cloudTrailLookupInterval)
+	defer throttle.Stop()
+
+	threshold := time.Now().AddDate(0, 0, -days)
+	keyList := make([]string, 0)
+	for _, key := range keys {
+		if referencedKeys[*key.KeyId] || key.CreationDate.After(threshold) {
+			continue
+		}
+		used, err := hasRecentCryptoEvents(ctSvc, throttle, key.Arn, threshold)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			continue
+		}
+
+		keyEntry := fmt.Sprint(*key.KeyId, ", created: ", key.CreationDate.Format(time.RFC3339),
+			", spec: ", aws.StringValue(key.KeySpec), ", cost: ", formatMonthlyCost(kmsKeyMonthlyPrice))
+		if description := aws.StringValue(key.Description); description != "" {
+			keyEntry = keyEntry + ", description: " + description
+		}
+		keyList = append(keyList, keyEntry)
+	}
+
+	return []Result{{"KMS keys not used and not referenced:", keyList}}, nil
+}
+
+// hasRecentCryptoEvents checks whether CloudTrail recorded a cryptographic operation with the key since
+// the given time, each LookupEvents call waits for the throttle. Note that CloudTrail event history only
+// covers the last 90 days
+func hasRecentCryptoEvents(ctSvc *cloudtrail.CloudTrail, throttle *time.Ticker, keyArn *string, since time.Time) (bool, error) {
+	input := &cloudtrail.LookupEventsInput{
+		LookupAttributes: []*cloudtrail.LookupAttribute{{
+			AttributeKey:   aws.String(cloudtrail.LookupAttributeKeyResourceName),
+			AttributeValue: keyArn,
+		}},
+		StartTime: aws.Time(since),
+	}
+	found := false
+	<-throttle.C
+	err := ctSvc.LookupEventsPages(input, func(page *cloudtrail.LookupEventsOutput, lastPage bool) bool {
+		for _, event := range page.Events {
+			if kmsCryptoEvents[aws.StringValue(event.EventName)] {
+				found = true
+				return false
+			}
+		}
+		if !lastPage {
+			<-throttle.C
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return false, fmt.Errorf("error looking up events of %v: %w", *keyArn, err)
+	}
+	return found, nil
+}
+
+// getReferencedKeyIDs returns IDs of the keys used to encrypt EBS volumes, RDS instances and clusters,
+// and configured as default encryption keys of S3 buckets
+func getReferencedKeyIDs(sess *session.Session, aliases map[string]string) (map[string]bool, error) {
+	keyRefs := make([]*string, 0)
+
+	volumes, err := describeVolumes(nil, nil, ec2.New(sess))
+	if err != nil {
+		return nil, err
+	}
+	for _, volume := range volumes {
+		keyRefs = append(keyRefs, volume.KmsKeyId)
+	}
+
+	rdsSvc := rds.New(sess)
+	err = rdsSvc.DescribeDBInstancesPages(&rds.DescribeDBInstancesInput{}, func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+		for _, instance := range page.DBInstances {
+			keyRefs = append(keyRefs, instance.KmsKeyId, instance.PerformanceInsightsKMSKeyId)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing RDS instances: %w", err)
+	}
+	err = rdsSvc.DescribeDBClustersPages(&rds.DescribeDBClustersInput{}, func(page *rds.DescribeDBClustersOutput, lastPage bool) bool {
+		for _, cluster := range page.DBClusters {
+			keyRefs = append(keyRefs, cluster.KmsKeyId)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing RDS clusters: %w", err)
+	}
+
+	buckets, err := listBuckets(sess)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range buckets {
+		output, err := b.s3Svc.GetBucketEncryption(&s3.GetBucketEncryptionInput{Bucket: b.name})
+		if err != nil {
+			if isErrorCode(err, "ServerSideEncryptionConfigurationNotFoundError") {
+				continue
+			}
+			return nil, fmt.Errorf("error getting encryption of %v: %w", *b.name, err)
+		}
+		for _, rule := range output.ServerSideEncryptionConfiguration.Rules {
+			if rule.ApplyServerSideEncryptionByDefault != nil {
+				keyRefs = append(keyRefs, rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID)
+			}
+		}
+	}
+
+	referencedKeys := make(map[string]bool)
+	for _, keyRef := range keyRefs {
+		if keyID := resolveKeyID(aws.StringValue(keyRef), aliases); keyID != "" {
+			referencedKeys[keyID] = true
+		}
+	}
+	return referencedKeys, nil
+}
+
+// resolveKeyID returns the key ID of a key ID, key ARN, alias name or alias ARN reference
+func resolveKeyID(keyRef string, aliases map[string]string) string {
+	if i := strings.Index(keyRef, "alias/"); i >= 0 {
+		return aliases[keyRef[i:]]
+	}
+	if i := strings.LastIndex(keyRef, "key/"); i >= 0 {
+		return keyRef[i+len("key/"):]
+	}
+	return keyRef
+}
+
+// getKeyAliases returns key IDs by alias names
+func getKeyAliases(kmsSvc *kms.KMS) (map[string]string, error) {
+	aliases := make(map[string]string)
+	err := kmsSvc.ListAliasesPages(&kms.ListAliasesInput{}, func(page *kms.ListAliasesOutput, lastPage bool) bool {
+		for _, alias := range page.Aliases {
+			if alias.TargetKeyId != nil {
+				aliases[*alias.AliasName] = *alias.TargetKeyId
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing KMS aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// describeCustomerKeys returns enabled customer managed keys
+func describeCustomerKeys(kmsSvc *kms.KMS) ([]*kms.KeyMetadata, error) {
+	keyIDs := make([]*string, 0)
+	err := kmsSvc.ListKeysPages(&kms.ListKeysInput{}, func(page *kms.ListKeysOutput, lastPage bool) bool {
+		for _, key := range page.Keys {
+			keyIDs = append(keyIDs, key.KeyId)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing KMS keys: %w", err)
+	}
+
+	keys := make([]*kms.KeyMetadata, 0, len(keyIDs))
+	for _, keyID := range keyIDs {
+		output, err := kmsSvc.DescribeKey(&kms.DescribeKeyInput{KeyId: keyID})
+		if err != nil {
+			return nil, fmt.Errorf("error describing KMS key %v: %w", *keyID, err)
+		}
+		key := output.KeyMetadata
+		if aws.StringValue(key.KeyManager) == kms.KeyManagerTypeCustomer && aws.StringValue(key.KeyState) == kms.KeyStateEnabled {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// ListUnusedLambdas lists Lambda functions not invoked for the last given number of days,
+// functions without event sources or triggers, and published versions not referenced by any alias
+func ListUnusedLambdas(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	lambdaSvc := lambda.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	functions, err := listFunctions(lambdaSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	notInvokedList := make([]string, 0)
+	noTriggerList := make([]string, 0)
+	staleVersionList := make([]string, 0)
+	staleCodeSize := int64(0)
+	for _, function := range functions {
+		functionEntry := fmt.Sprint(*function.FunctionName, ", runtime: ", aws.StringValue(function.Runtime),
+			", code size: ", aws.Int64Value(function.CodeSize), " bytes")
+
+		dimensions := newDimension("FunctionName", function.FunctionName)
+		invocations, err := sumMetric(cwSvc, "AWS/Lambda", "Invocations", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *function.FunctionName, err)
+		}
+		if invocations == 0 {
+			notInvokedList = append(notInvokedList, functionEntry)
+		}
+
+		triggered, err := hasTriggers(lambdaSvc, function.FunctionName)
+		if err != nil {
+			return nil, err
+		}
+		if !triggered {
+			noTriggerList = append(noTriggerList, functionEntry)
+		}
+
+		versions, err := listStaleVersions(lambdaSvc, function.FunctionName)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			staleCodeSize += aws.Int64Value(version.CodeSize)
+			versionEntry := fmt.Sprint(*function.FunctionName, ":", *version.Version, ", code size: ", aws.Int64Value(version.CodeSize), " bytes")
+			staleVersionList = append(staleVersionList, versionEntry)
+		}
+	}
+
+	staleVersionLabel := fmt.Sprintf("Lambda versions not referenced by any alias (%d bytes):", staleCodeSize)
+	settings, err := lambdaSvc.GetAccountSettings(&lambda.GetAccountSettingsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting Lambda account settings: %w", err)
+	}
+	if settings.AccountLimit != nil && settings.AccountUsage != nil {
+		staleVersionLabel = fmt.Sprintf("Lambda versions not referenced by any alias (%d bytes, code storage used: %d of %d bytes):",
+			staleCodeSize, aws.Int64Value(settings.AccountUsage.TotalCodeSize), aws.Int64Value(settings.AccountLimit.TotalCodeSize))
+	}
+
+	return []Result{
+		{fmt.Sprintf("Lambda functions not invoked for %d days:", days), notInvokedList},
+		{"Lambda functions without event sources or triggers:", noTriggerList},
+		{staleVersionLabel, staleVersionList},
+	}, nil
+}
+
+// hasTriggers reports whether the function has an event source mapping or a resource-based
+// policy allowing other services, like S3, SNS or API Gateway, to invoke it
+func hasTriggers(lambdaSvc *lambda.Lambda, functionName *string) (bool, error) {
+	mappingsInput := &lambda.ListEventSourceMappingsInput{
+		FunctionName: functionName,
+	}
+	mappings, err := lambdaSvc.ListEventSourceMappings(mappingsInput)
+	if err != nil {
+		return false, fmt.Errorf("error listing event source mappings for %v: %w", *functionName, err)
+	}
+	if len(mappings.EventSourceMappings) > 0 {
+		return true, nil
+	}
+
+	policyInput := &lambda.GetPolicyInput{
+		FunctionName: functionName,
+	}
+	_, err = lambdaSvc.GetPolicy(policyInput)
+	if isErrorCode(err, lambda.ErrCodeResourceNotFoundException) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error getting policy for %v: %w", *functionName, err)
+	}
+	return true, nil
+}
+
+// listStaleVersions returns published versions of the function which are neither the latest
+// published version nor referenced by an alias
+func listStaleVersions(lambdaSvc *lambda.Lambda, functionName *string) ([]*lambda.FunctionConfiguration, error) {
+	referenced := make(map[string]bool)
+	aliasesInput := &lambda.ListAliasesInput{
+		FunctionName: functionName,
+	}
+	err := lambdaSvc.ListAliasesPages(aliasesInput, func(page *lambda.ListAliasesOutput, lastPage bool) bool {
+		for _, alias := range page.Aliases {
+			referenced[*alias.FunctionVersion] = true
+			if alias.RoutingConfig != nil {
+				for version := range alias.RoutingConfig.AdditionalVersionWeights {
+					referenced[version] = true
+				}
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing aliases for %v: %w", *functionName, err)
+	}
+
+	versions := make([]*lambda.FunctionConfiguration, 0)
+	latestVersion := int64(0)
+	versionsInput := &lambda.ListVersionsByFunctionInput{
+		FunctionName: functionName,
+	}
+	err = lambdaSvc.ListVersionsByFunctionPages(versionsInput, func(page *lambda.ListVersionsByFunctionOutput, lastPage bool) bool {
+		for _, version := range page.Versions {
+			number, err := strconv.ParseInt(*version.Version, 10, 64)
+			if err != nil {
+				// $LATEST
+				continue
+			}
+			if number > latestVersion {
+				latestVersion = number
+			}
+			if !referenced[*version.Version] {
+				versions = append(versions, version)
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing versions for %v: %w", *functionName, err)
+	}
+
+	staleVersions := make([]*lambda.FunctionConfiguration, 0, len(versions))
+	for _, version := range versions {
+		if *version.Version != strconv.FormatInt(latestVersion, 10) {
+			staleVersions = append(staleVersions, version)
+		}
+	}
+	return staleVersions, nil
+}
+
+func listFunctions(lambdaSvc *lambda.Lambda) ([]*lambda.FunctionConfiguration, error) {
+	functions := make([]*lambda.FunctionConfiguration, 0)
+	input := &lambda.ListFunctionsInput{}
+	err := lambdaSvc.ListFunctionsPages(input, func(page *lambda.ListFunctionsOutput, lastPage bool) bool {
+		functions = append(functions, page.Functions...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing Lambda functions: %w", err)
+	}
+
+	return functions, nil
+}
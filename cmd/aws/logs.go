@@ -0,0 +1,155 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/lambda"
+)
+
+// Log group name prefixes of Lambda functions, ECS task definition families created by the ECS console
+// and ECS Container Insights
+const (
+	lambdaLogGroupPrefix            = "/aws/lambda/"
+	ecsLogGroupPrefix               = "/ecs/"
+	containerInsightsLogGroupPrefix = "/aws/ecs/containerinsights/"
+)
+
+// lambdaEdgeRegion is the region Lambda@Edge functions are created in
+const lambdaEdgeRegion = "us-east-1"
+
+// ListUnusedLogGroups lists CloudWatch Logs groups without retention, with no ingestion for the last
+// given number of days, and groups of deleted Lambda functions and ECS task definitions or clusters
+func ListUnusedLogGroups(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	logsSvc := cloudwatchlogs.New(sess)
+	cwSvc := cloudwatch.New(sess)
+	ecsSvc := ecs.New(sess)
+
+	logGroups, err := describeLogGroups(logsSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	functionNames, err := listFunctionNames(lambda.New(sess))
+	if err != nil {
+		return nil, err
+	}
+	// Lambda@Edge replicas log to /aws/lambda/<region>.<function> in the region they ran in
+	edgeFunctionNames := functionNames
+	region := sessionRegion(sess)
+	if region != lambdaEdgeRegion && partitionForRegion(region) == endpoints.AwsPartitionID {
+		edgeFunctionNames, err = listFunctionNames(lambda.New(sess.Copy(aws.NewConfig().WithRegion(lambdaEdgeRegion))))
+		if err != nil {
+			return nil, err
+		}
+	}
+	families, err := listActiveTaskDefinitionFamilies(ecsSvc)
+	if err != nil {
+		return nil, err
+	}
+	clusterARNs, err := listClusterARNs(ecsSvc)
+	if err != nil {
+		return nil, err
+	}
+	clusterNames := make(map[string]bool)
+	for _, clusterARN := range clusterARNs {
+		clusterNames[clusterName(*clusterARN)] = true
+	}
+
+	noRetentionList := make([]string, 0)
+	noIngestionList := make([]string, 0)
+	orphanedList := make([]string, 0)
+	for _, logGroup := range logGroups {
+		name := *logGroup.LogGroupName
+		storedBytes := aws.Int64Value(logGroup.StoredBytes)
+		monthlyCost := float64(storedBytes) / bytesPerGB * logsGBMonthPrice
+		logGroupEntry := fmt.Sprint(name, ", stored: ", storedBytes, " bytes, cost: ", formatMonthlyCost(monthlyCost))
+
+		if logGroup.RetentionInDays == nil {
+			noRetentionList = append(noRetentionList, logGroupEntry)
+		}
+
+		switch {
+		case strings.HasPrefix(name, lambdaLogGroupPrefix) &&
+			!isExistingFunctionLogGroup(strings.TrimPrefix(name, lambdaLogGroupPrefix), functionNames, edgeFunctionNames):
+			orphanedList = append(orphanedList, logGroupEntry+", deleted Lambda function")
+		case strings.HasPrefix(name, ecsLogGroupPrefix) && !families[strings.TrimPrefix(name, ecsLogGroupPrefix)]:
+			orphanedList = append(orphanedList, logGroupEntry+", deleted ECS task definition")
+		case strings.HasPrefix(name, containerInsightsLogGroupPrefix) &&
+			!clusterNames[strings.SplitN(strings.TrimPrefix(name, containerInsightsLogGroupPrefix), "/", 2)[0]]:
+			orphanedList = append(orphanedList, logGroupEntry+", deleted ECS cluster")
+		}
+
+		incomingBytes, err := sumMetric(cwSvc, "AWS/Logs", "IncomingBytes", newDimension("LogGroupName", logGroup.LogGroupName), days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", name, err)
+		}
+		if incomingBytes == 0 {
+			noIngestionList = append(noIngestionList, logGroupEntry)
+		}
+	}
+
+	return []Result{
+		{"Log groups without retention:", noRetentionList},
+		{fmt.Sprintf("Log groups with no ingestion for %d days:", days), noIngestionList},
+		{"Log groups of deleted resources:", orphanedList},
+	}, nil
+}
+
+// isExistingFunctionLogGroup checks whether the function of a Lambda log group exists, function names
+// can't contain dots, so a dot separates the region prefix of Lambda@Edge log groups
+func isExistingFunctionLogGroup(name string, functionNames, edgeFunctionNames map[string]bool) bool {
+	if parts := strings.SplitN(name, ".", 2); len(parts) == 2 {
+		return edgeFunctionNames[parts[1]]
+	}
+	return functionNames[name]
+}
+
+func listFunctionNames(lambdaSvc *lambda.Lambda) (map[string]bool, error) {
+	functions, err := listFunctions(lambdaSvc)
+	if err != nil {
+		return nil, err
+	}
+	functionNames := make(map[string]bool)
+	for _, function := range functions {
+		functionNames[*function.FunctionName] = true
+	}
+	return functionNames, nil
+}
+
+func describeLogGroups(logsSvc *cloudwatchlogs.CloudWatchLogs) ([]*cloudwatchlogs.LogGroup, error) {
+	logGroups := make([]*cloudwatchlogs.LogGroup, 0)
+	input := &cloudwatchlogs.DescribeLogGroupsInput{}
+	err := logsSvc.DescribeLogGroupsPages(input, func(page *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+		logGroups = append(logGroups, page.LogGroups...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing log groups: %w", err)
+	}
+	return logGroups, nil
+}
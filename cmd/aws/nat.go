@@ -0,0 +1,145 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// idleNATBytesThreshold is the amount of traffic sent to destinations over the whole
+// lookback window below which a NAT gateway is considered idle
+const idleNATBytesThreshold = 100 * 1024 * 1024
+
+// ListUnusedNATGateways returns NAT gateways not referenced by any route table
+func ListUnusedNATGateways() ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ec2Svc := ec2.New(sess)
+
+	natGateways, err := describeAvailableNATGateways(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
+	routedNATGateways, err := getRoutedNATGatewayIDs(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
+	natList := make([]string, 0)
+	for _, natGateway := range natGateways {
+		if !routedNATGateways[*natGateway.NatGatewayId] {
+			natEntry := fmt.Sprint(formatNATGateway(natGateway), ", cost: ", formatHourlyCost(natGatewayHourlyPrice))
+			natList = append(natList, natEntry)
+		}
+	}
+
+	return []Result{{"NAT gateways not referenced by route tables:", natList}}, nil
+}
+
+// ListIdleNATGateways returns NAT gateways with negligible traffic for the last given number of days
+func ListIdleNATGateways(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ec2Svc := ec2.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	natGateways, err := describeAvailableNATGateways(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
+	natList := make([]string, 0)
+	for _, natGateway := range natGateways {
+		dimensions := newDimension("NatGatewayId", natGateway.NatGatewayId)
+		bytesOut, err := sumMetric(cwSvc, "AWS/NATGateway", "BytesOutToDestination", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting NAT gateway %v metrics: %w", *natGateway.NatGatewayId, err)
+		}
+		if bytesOut >= idleNATBytesThreshold {
+			continue
+		}
+		connections, err := maxMetric(cwSvc, "AWS/NATGateway", "ActiveConnectionCount", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting NAT gateway %v metrics: %w", *natGateway.NatGatewayId, err)
+		}
+		if connections > 0 {
+			continue
+		}
+
+		processedGB := bytesOut / (1024 * 1024 * 1024)
+		hourlyCost := natGatewayHourlyPrice + processedGB*natGatewayPerGBPrice/float64(days*24)
+		natEntry := fmt.Sprintf("%s, bytes out: %.0f, cost: %s", formatNATGateway(natGateway), bytesOut, formatHourlyCost(hourlyCost))
+		natList = append(natList, natEntry)
+	}
+
+	return []Result{{"Idle NAT gateways:", natList}}, nil
+}
+
+func formatNATGateway(natGateway *ec2.NatGateway) string {
+	natEntry := fmt.Sprint(*natGateway.NatGatewayId, ", vpc: ", *natGateway.VpcId, ", subnet: ", *natGateway.SubnetId)
+	if name := getNameTag(natGateway.Tags); name != nil {
+		natEntry = *name + ", " + natEntry
+	}
+	return natEntry
+}
+
+func getRoutedNATGatewayIDs(ec2Svc *ec2.EC2) (map[string]bool, error) {
+	natGatewayIDs := make(map[string]bool)
+	input := &ec2.DescribeRouteTablesInput{}
+	err := ec2Svc.DescribeRouteTablesPages(input, func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+		for _, routeTable := range page.RouteTables {
+			for _, route := range routeTable.Routes {
+				if route.NatGatewayId != nil {
+					natGatewayIDs[*route.NatGatewayId] = true
+				}
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing route tables: %w", err)
+	}
+
+	return natGatewayIDs, nil
+}
+
+func describeAvailableNATGateways(ec2Svc *ec2.EC2) ([]*ec2.NatGateway, error) {
+	natGateways := make([]*ec2.NatGateway, 0)
+	input := &ec2.DescribeNatGatewaysInput{
+		Filter: []*ec2.Filter{{
+			Name:   aws.String("state"),
+			Values: aws.StringSlice([]string{"available"}),
+		}},
+	}
+	err := ec2Svc.DescribeNatGatewaysPages(input, func(page *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+		natGateways = append(natGateways, page.NatGateways...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing NAT gateways: %w", err)
+	}
+
+	return natGateways, nil
+}
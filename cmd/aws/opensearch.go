@@ -0,0 +1,100 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/opensearchservice"
+)
+
+// ListIdleOpenSearchDomains lists OpenSearch domains with no search and indexing requests
+// for the last given number of days
+func ListIdleOpenSearchDomains(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	openSearchSvc := opensearchservice.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	accountID, err := getAccountID(sess)
+	if err != nil {
+		return nil, err
+	}
+	domains, err := describeDomains(openSearchSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	idleList := make([]string, 0)
+	for _, domain := range domains {
+		// OpenSearch metrics are published under the AWS/ES namespace
+		dimensions := append(newDimension("DomainName", domain.DomainName), newDimension("ClientId", aws.String(accountID))...)
+		idle := true
+		for _, metricName := range []string{"SearchRate", "IndexingRate"} {
+			rate, err := sumMetric(cwSvc, "AWS/ES", metricName, dimensions, days)
+			if err != nil {
+				return nil, fmt.Errorf("error getting %v metrics: %w", *domain.DomainName, err)
+			}
+			if rate > 0 {
+				idle = false
+				break
+			}
+		}
+		if !idle {
+			continue
+		}
+
+		domainEntry := fmt.Sprint(*domain.DomainName, ", engine: ", aws.StringValue(domain.EngineVersion))
+		if config := domain.ClusterConfig; config != nil {
+			domainEntry = fmt.Sprint(domainEntry, ", instance type: ", aws.StringValue(config.InstanceType),
+				", instances: ", aws.Int64Value(config.InstanceCount))
+		}
+		idleList = append(idleList, domainEntry)
+	}
+
+	return []Result{{"Idle OpenSearch domains:", idleList}}, nil
+}
+
+func describeDomains(openSearchSvc *opensearchservice.OpenSearchService) ([]*opensearchservice.DomainStatus, error) {
+	names, err := openSearchSvc.ListDomainNames(&opensearchservice.ListDomainNamesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing OpenSearch domains: %w", err)
+	}
+
+	// DescribeDomains accepts up to 5 domains
+	const batchSize = 5
+	domains := make([]*opensearchservice.DomainStatus, 0, len(names.DomainNames))
+	for start := 0; start < len(names.DomainNames); start += batchSize {
+		end := start + batchSize
+		if end > len(names.DomainNames) {
+			end = len(names.DomainNames)
+		}
+		domainNames := make([]*string, 0, end-start)
+		for _, info := range names.DomainNames[start:end] {
+			domainNames = append(domainNames, info.DomainName)
+		}
+		output, err := openSearchSvc.DescribeDomains(&opensearchservice.DescribeDomainsInput{DomainNames: domainNames})
+		if err != nil {
+			return nil, fmt.Errorf("error describing OpenSearch domains: %w", err)
+		}
+		domains = append(domains, output.DomainStatusList...)
+	}
+	return domains, nil
+}
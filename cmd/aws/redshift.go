@@ -0,0 +1,120 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/redshift"
+)
+
+// ListIdleRedshiftClusters lists Redshift clusters with no database connections and no completed queries
+// for the last given number of days
+func ListIdleRedshiftClusters(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	redshiftSvc := redshift.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	clusters, err := describeRedshiftClusters(redshiftSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	idleList := make([]string, 0)
+	for _, cluster := range clusters {
+		dimensions := newDimension("ClusterIdentifier", cluster.ClusterIdentifier)
+		connections, err := maxMetric(cwSvc, "AWS/Redshift", "DatabaseConnections", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *cluster.ClusterIdentifier, err)
+		}
+		if connections > 0 {
+			continue
+		}
+
+		queries := 0.0
+		for _, latency := range []string{"short", "medium", "long"} {
+			queryDimensions := append(newDimension("ClusterIdentifier", cluster.ClusterIdentifier), newDimension("latency", aws.String(latency))...)
+			count, err := sumMetric(cwSvc, "AWS/Redshift", "QueriesCompletedPerSecond", queryDimensions, days)
+			if err != nil {
+				return nil, fmt.Errorf("error getting %v metrics: %w", *cluster.ClusterIdentifier, err)
+			}
+			queries += count
+		}
+		if queries > 0 {
+			continue
+		}
+
+		clusterEntry := fmt.Sprint(*cluster.ClusterIdentifier, ", node type: ", aws.StringValue(cluster.NodeType),
+			", nodes: ", aws.Int64Value(cluster.NumberOfNodes), ", status: ", aws.StringValue(cluster.ClusterStatus))
+		idleList = append(idleList, clusterEntry)
+	}
+
+	return []Result{{"Idle Redshift clusters:", idleList}}, nil
+}
+
+// listOrphanedRedshiftSnapshots returns manual Redshift snapshots of clusters which no longer exist
+func listOrphanedRedshiftSnapshots(redshiftSvc *redshift.Redshift) ([]string, error) {
+	clusters, err := describeRedshiftClusters(redshiftSvc)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool)
+	for _, cluster := range clusters {
+		existing[*cluster.ClusterIdentifier] = true
+	}
+
+	snapshotList := make([]string, 0)
+	input := &redshift.DescribeClusterSnapshotsInput{
+		SnapshotType: aws.String("manual"),
+	}
+	err = redshiftSvc.DescribeClusterSnapshotsPages(input, func(page *redshift.DescribeClusterSnapshotsOutput, lastPage bool) bool {
+		for _, snapshot := range page.Snapshots {
+			if existing[aws.StringValue(snapshot.ClusterIdentifier)] {
+				continue
+			}
+			sizeGB := aws.Float64Value(snapshot.TotalBackupSizeInMegaBytes) / 1024
+			snapshotEntry := fmt.Sprintf("%s, cluster: %s, size: %.2f GB, cost: %s", *snapshot.SnapshotIdentifier,
+				aws.StringValue(snapshot.ClusterIdentifier), sizeGB, formatMonthlyCost(sizeGB*redshiftSnapshotGBMonthPrice))
+			if snapshot.SnapshotCreateTime != nil {
+				snapshotEntry = snapshotEntry + ", created: " + snapshot.SnapshotCreateTime.Format(time.RFC3339)
+			}
+			snapshotList = append(snapshotList, snapshotEntry)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing Redshift snapshots: %w", err)
+	}
+	return snapshotList, nil
+}
+
+func describeRedshiftClusters(redshiftSvc *redshift.Redshift) ([]*redshift.Cluster, error) {
+	clusters := make([]*redshift.Cluster, 0)
+	err := redshiftSvc.DescribeClustersPages(&redshift.DescribeClustersInput{}, func(page *redshift.DescribeClustersOutput, lastPage bool) bool {
+		clusters = append(clusters, page.Clusters...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing Redshift clusters: %w", err)
+	}
+	return clusters, nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const (
+	// rightsizePercentile is the utilization percentile instances are sized for
+	rightsizePercentile = "p95"
+	// targetCPUPercent and targetMemoryPercent are the utilizations the recommended type should run at
+	targetCPUPercent     = 60
+	targetMemoryPercent  = 80
+	targetNetworkPercent = 60
+)
+
+// RightsizeEC2s recommends smaller instance types for running EC2 instances
+// based on their utilization for the last given number of days
+func RightsizeEC2s(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ec2Svc := ec2.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	filter := &ec2.Filter{
+		Name:   aws.String("instance-state-name"),
+		Values: aws.StringSlice([]string{"running"}),
+	}
+	instances, err := describeEC2Instances(nil, []*ec2.Filter{filter}, ec2Svc)
+	if err != nil {
+		return nil, fmt.Errorf("error describing EC2 instances: %w", err)
+	}
+
+	rightsizeList := make([]string, 0)
+	for _, instance := range instances {
+		instanceType := *instance.InstanceType
+		current, ok := instanceCatalog[instanceType]
+		if !ok {
+			continue
+		}
+
+		dimensions := newDimension("InstanceId", instance.InstanceId)
+		cpuPercent, ok, err := percentileMetric(cwSvc, "AWS/EC2", "CPUUtilization", rightsizePercentile, dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *instance.InstanceId, err)
+		}
+		if !ok {
+			continue
+		}
+		// network metrics are bytes per sample, i.e. per 5 minutes or per minute with detailed monitoring
+		sampleSeconds := 300.0
+		if instance.Monitoring != nil && aws.StringValue(instance.Monitoring.State) == ec2.MonitoringStateEnabled {
+			sampleSeconds = 60
+		}
+		networkGbps := 0.0
+		for _, metricName := range []string{"NetworkIn", "NetworkOut"} {
+			bytes, _, err := percentileMetric(cwSvc, "AWS/EC2", metricName, rightsizePercentile, dimensions, days)
+			if err != nil {
+				return nil, fmt.Errorf("error getting %v metrics: %w", *instance.InstanceId, err)
+			}
+			// inbound and outbound traffic don't share bandwidth, so the busier direction counts
+			networkGbps = math.Max(networkGbps, bytes*8/sampleSeconds/1e9)
+		}
+		memoryPercent, memoryKnown, err := instanceMemoryPercent(cwSvc, instance, days)
+		if err != nil {
+			return nil, err
+		}
+
+		requiredVCPUs := float64(current.vCPUs) * cpuPercent / targetCPUPercent
+		// without memory metrics the instance is only allowed to go one size down
+		requiredMemory := current.memoryGiB / 2
+		if memoryKnown {
+			requiredMemory = current.memoryGiB * memoryPercent / targetMemoryPercent
+		}
+		requiredNetwork := networkGbps * 100 / targetNetworkPercent
+
+		family := instanceFamily(instanceType)
+		recommended := smallestFittingType(family, requiredVCPUs, requiredMemory, requiredNetwork)
+		if recommended == "" || instanceCatalog[recommended].hourlyPrice >= current.hourlyPrice {
+			recommended = instanceType
+		}
+		graviton := ""
+		// Graviton instances don't run Windows
+		if gravitonFamily, ok := gravitonFamilies[family]; ok && aws.StringValue(instance.Platform) != ec2.PlatformValuesWindows {
+			graviton = smallestFittingType(gravitonFamily, requiredVCPUs, requiredMemory, requiredNetwork)
+		}
+		if recommended == instanceType && graviton == "" {
+			continue
+		}
+
+		memoryEntry := "n/a"
+		if memoryKnown {
+			memoryEntry = fmt.Sprintf("%.0f%%", memoryPercent)
+		}
+		ec2Entry := fmt.Sprintf("%s, type: %s, cpu %s: %.0f%%, memory %s: %s, network %s: %.3f Gbps",
+			*instance.InstanceId, instanceType, rightsizePercentile, cpuPercent, rightsizePercentile, memoryEntry,
+			rightsizePercentile, networkGbps)
+		if name := getNameTag(instance.Tags); name != nil {
+			ec2Entry = *name + ", " + ec2Entry
+		}
+		if recommended != instanceType {
+			savings := (current.hourlyPrice - instanceCatalog[recommended].hourlyPrice) * hoursPerMonth
+			ec2Entry = fmt.Sprint(ec2Entry, ", recommended: ", recommended, " (savings: ", formatMonthlyCost(savings), ")")
+		}
+		if graviton != "" {
+			savings := (current.hourlyPrice - instanceCatalog[graviton].hourlyPrice) * hoursPerMonth
+			if savings > 0 {
+				ec2Entry = fmt.Sprint(ec2Entry, ", graviton: ", graviton, " (savings: ", formatMonthlyCost(savings), ")")
+			} else if recommended == instanceType {
+				continue
+			}
+		}
+		rightsizeList = append(rightsizeList, ec2Entry)
+	}
+
+	return []Result{{"EC2 instances to rightsize:", rightsizeList}}, nil
+}
+
+// instanceMemoryPercent returns the memory usage percentile reported by the CloudWatch agent for the instance
+func instanceMemoryPercent(cwSvc *cloudwatch.CloudWatch, instance *ec2.Instance, days int) (float64, bool, error) {
+	metrics, err := listAgentMetrics(cwSvc, "mem_used_percent", *instance.InstanceId)
+	if err != nil {
+		return 0, false, err
+	}
+	// metrics of a previous instance type are kept after a resize, use the current one
+	var metric *cloudwatch.Metric
+	for _, m := range metrics {
+		if len(metrics) == 1 || dimensionValue(m.Dimensions, "InstanceType") == *instance.InstanceType {
+			metric = m
+			break
+		}
+	}
+	if metric == nil {
+		return 0, false, nil
+	}
+
+	memoryPercent, ok, err := percentileMetric(cwSvc, "CWAgent", "mem_used_percent", rightsizePercentile, metric.Dimensions, days)
+	if err != nil {
+		return 0, false, fmt.Errorf("error getting %v metrics: %w", *instance.InstanceId, err)
+	}
+	return memoryPercent, ok, nil
+}
+
+func instanceFamily(instanceType string) string {
+	return strings.SplitN(instanceType, ".", 2)[0]
+}
+
+// smallestFittingType returns the cheapest instance type of the family from the catalog
+// with at least the given vCPUs, memory and baseline network bandwidth, or an empty string if there is none
+func smallestFittingType(family string, vCPUs, memoryGiB, networkGbps float64) string {
+	candidates := make([]string, 0)
+	for instanceType, info := range instanceCatalog {
+		if instanceFamily(instanceType) == family && float64(info.vCPUs) >= math.Ceil(vCPUs) &&
+			info.memoryGiB >= memoryGiB && info.networkGbps >= networkGbps {
+			candidates = append(candidates, instanceType)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return instanceCatalog[candidates[i]].hourlyPrice < instanceCatalog[candidates[j]].hourlyPrice
+	})
+	return candidates[0]
+}
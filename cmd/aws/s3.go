@@ -0,0 +1,295 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const bytesPerGB = 1024 * 1024 * 1024
+
+// bucket is an S3 bucket with clients for the region it is located in,
+// since S3 storage and request metrics are only available there
+type bucket struct {
+	name   *string
+	region string
+	s3Svc  *s3.S3
+	cwSvc  *cloudwatch.CloudWatch
+}
+
+// ListUnusedS3Buckets lists empty and inactive S3 buckets, buckets accumulating noncurrent versions
+// or incomplete multipart uploads without lifecycle rules, and the storage cost of each bucket
+func ListUnusedS3Buckets(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := listBuckets(sess)
+	if err != nil {
+		return nil, err
+	}
+
+	emptyList := make([]string, 0)
+	inactiveList := make([]string, 0)
+	noncurrentList := make([]string, 0)
+	multipartList := make([]string, 0)
+	storageList := make([]string, 0)
+	totalCost := 0.0
+	for _, b := range buckets {
+		bucketEntry := fmt.Sprint(*b.name, ", region: ", b.region)
+
+		storage, err := getBucketStorage(b)
+		if err != nil {
+			return nil, err
+		}
+		monthlyCost := 0.0
+		storageEntries := make([]string, 0, len(storage))
+		for _, storageType := range sortedStorageTypes(storage) {
+			size := storage[storageType]
+			monthlyCost += size / bytesPerGB * s3StorageGBMonthPrices[storageType]
+			storageEntries = append(storageEntries, fmt.Sprintf("%s: %.2f GB", storageType, size/bytesPerGB))
+		}
+		totalCost += monthlyCost
+		storageList = append(storageList, fmt.Sprint(bucketEntry, ", ", strings.Join(storageEntries, ", "), ", cost: ", formatMonthlyCost(monthlyCost)))
+
+		versions, err := b.s3Svc.ListObjectVersions(&s3.ListObjectVersionsInput{Bucket: b.name, MaxKeys: aws.Int64(1)})
+		if err != nil {
+			return nil, fmt.Errorf("error listing object versions of %v: %w", *b.name, err)
+		}
+		if len(versions.Versions) == 0 && len(versions.DeleteMarkers) == 0 {
+			emptyList = append(emptyList, bucketEntry)
+			continue
+		}
+
+		inactive, reason, err := isInactiveBucket(b, days)
+		if err != nil {
+			return nil, err
+		}
+		if inactive {
+			inactiveList = append(inactiveList, bucketEntry+", "+reason)
+		}
+
+		rules, err := getLifecycleRules(b)
+		if err != nil {
+			return nil, err
+		}
+		noncurrent, err := hasNoncurrentVersions(b)
+		if err != nil {
+			return nil, err
+		}
+		if noncurrent && !hasNoncurrentVersionExpiration(rules) {
+			noncurrentList = append(noncurrentList, bucketEntry)
+		}
+		uploads, err := b.s3Svc.ListMultipartUploads(&s3.ListMultipartUploadsInput{Bucket: b.name, MaxUploads: aws.Int64(1)})
+		if err != nil {
+			return nil, fmt.Errorf("error listing multipart uploads of %v: %w", *b.name, err)
+		}
+		if len(uploads.Uploads) > 0 && !hasAbortIncompleteMultipartUpload(rules) {
+			multipartList = append(multipartList, bucketEntry)
+		}
+	}
+
+	return []Result{
+		{"Empty S3 buckets:", emptyList},
+		{fmt.Sprintf("S3 buckets with no activity for %d days:", days), inactiveList},
+		{"S3 buckets with noncurrent versions and no lifecycle rule expiring them:", noncurrentList},
+		{"S3 buckets with incomplete multipart uploads and no lifecycle rule aborting them:", multipartList},
+		{fmt.Sprintf("S3 storage by class (%s):", formatMonthlyCost(totalCost)), storageList},
+	}, nil
+}
+
+// getBucketStorage returns the latest bucket size in bytes per storage type
+func getBucketStorage(b *bucket) (map[string]float64, error) {
+	input := &cloudwatch.ListMetricsInput{
+		Namespace:  aws.String("AWS/S3"),
+		MetricName: aws.String("BucketSizeBytes"),
+		Dimensions: []*cloudwatch.DimensionFilter{{
+			Name:  aws.String("BucketName"),
+			Value: b.name,
+		}},
+	}
+	metrics := make([]*cloudwatch.Metric, 0)
+	err := b.cwSvc.ListMetricsPages(input, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+		metrics = append(metrics, page.Metrics...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing metrics of %v: %w", *b.name, err)
+	}
+
+	storage := make(map[string]float64)
+	for _, metric := range metrics {
+		var storageType string
+		for _, dimension := range metric.Dimensions {
+			if *dimension.Name == "StorageType" {
+				storageType = *dimension.Value
+			}
+		}
+		// storage metrics are reported once a day
+		size, err := maxMetric(b.cwSvc, "AWS/S3", "BucketSizeBytes", metric.Dimensions, 2)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %v metrics: %w", *b.name, err)
+		}
+		if size > 0 {
+			storage[storageType] = size
+		}
+	}
+	return storage, nil
+}
+
+// isInactiveBucket reports whether the bucket had no GET or PUT requests for the last given number
+// of days, with the reason. Buckets without request metrics configured are reported when their size
+// did not change, which says nothing about reads.
+func isInactiveBucket(b *bucket, days int) (bool, string, error) {
+	dimensions := append(newDimension("BucketName", b.name), newDimension("FilterId", aws.String("EntireBucket"))...)
+	hasRequestMetrics := false
+	for _, metricName := range []string{"GetRequests", "PutRequests"} {
+		datapoints, err := getMetricStatistics(b.cwSvc, "AWS/S3", metricName, "Sum", dimensions, days)
+		if err != nil {
+			return false, "", fmt.Errorf("error getting %v metrics: %w", *b.name, err)
+		}
+		for _, datapoint := range datapoints {
+			hasRequestMetrics = true
+			if *datapoint.Sum > 0 {
+				return false, "", nil
+			}
+		}
+	}
+	if hasRequestMetrics {
+		return true, "no requests", nil
+	}
+
+	// without request metrics reads can't be seen, an unchanged size only tells there were no writes
+
+	dimensions = append(newDimension("BucketName", b.name), newDimension("StorageType", aws.String("StandardStorage"))...)
+	datapoints, err := getMetricStatistics(b.cwSvc, "AWS/S3", "BucketSizeBytes", "Average", dimensions, days)
+	if err != nil {
+		return false, "", fmt.Errorf("error getting %v metrics: %w", *b.name, err)
+	}
+	if len(datapoints) < 2 {
+		return false, "", nil
+	}
+	for _, datapoint := range datapoints {
+		if *datapoint.Average != *datapoints[0].Average {
+			return false, "", nil
+		}
+	}
+	return true, "no request metrics; size unchanged", nil
+}
+
+func getLifecycleRules(b *bucket) ([]*s3.LifecycleRule, error) {
+	output, err := b.s3Svc.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{Bucket: b.name})
+	if isErrorCode(err, "NoSuchLifecycleConfiguration") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting lifecycle configuration of %v: %w", *b.name, err)
+	}
+	return output.Rules, nil
+}
+
+// hasNoncurrentVersions pages through the object versions of the bucket until it finds a noncurrent one,
+// buckets which never had versioning enabled are skipped as they can't have noncurrent versions
+func hasNoncurrentVersions(b *bucket) (bool, error) {
+	versioning, err := b.s3Svc.GetBucketVersioning(&s3.GetBucketVersioningInput{Bucket: b.name})
+	if err != nil {
+		return false, fmt.Errorf("error getting versioning of %v: %w", *b.name, err)
+	}
+	if versioning.Status == nil {
+		return false, nil
+	}
+
+	found := false
+	err = b.s3Svc.ListObjectVersionsPages(&s3.ListObjectVersionsInput{Bucket: b.name}, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		for _, version := range page.Versions {
+			if !aws.BoolValue(version.IsLatest) {
+				found = true
+				return false
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return false, fmt.Errorf("error listing object versions of %v: %w", *b.name, err)
+	}
+	return found, nil
+}
+
+func hasNoncurrentVersionExpiration(rules []*s3.LifecycleRule) bool {
+	for _, rule := range rules {
+		if aws.StringValue(rule.Status) == s3.ExpirationStatusEnabled && rule.NoncurrentVersionExpiration != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAbortIncompleteMultipartUpload(rules []*s3.LifecycleRule) bool {
+	for _, rule := range rules {
+		if aws.StringValue(rule.Status) == s3.ExpirationStatusEnabled && rule.AbortIncompleteMultipartUpload != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedStorageTypes(storage map[string]float64) []string {
+	storageTypes := make([]string, 0, len(storage))
+	for storageType := range storage {
+		storageTypes = append(storageTypes, storageType)
+	}
+	sort.Strings(storageTypes)
+	return storageTypes
+}
+
+// listBuckets returns all buckets of the account with clients for their regions
+func listBuckets(sess *session.Session) ([]*bucket, error) {
+	s3Svc := s3.New(sess)
+	output, err := s3Svc.ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing S3 buckets: %w", err)
+	}
+
+	regionSessions := make(map[string]*session.Session)
+	buckets := make([]*bucket, 0, len(output.Buckets))
+	for _, b := range output.Buckets {
+		location, err := s3Svc.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: b.Name})
+		if err != nil {
+			return nil, fmt.Errorf("error getting location of %v: %w", *b.Name, err)
+		}
+		region := s3.NormalizeBucketLocation(aws.StringValue(location.LocationConstraint))
+		regionSess, ok := regionSessions[region]
+		if !ok {
+			regionSess = sess.Copy(aws.NewConfig().WithRegion(region))
+			regionSessions[region] = regionSess
+		}
+		buckets = append(buckets, &bucket{
+			name:   b.Name,
+			region: region,
+			s3Svc:  s3.New(regionSess),
+			cwSvc:  cloudwatch.New(regionSess),
+		})
+	}
+	return buckets, nil
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// abortMultipartUploadRuleID is the ID of the proposed lifecycle rule aborting incomplete multipart uploads
+const abortMultipartUploadRuleID = "abort-incomplete-multipart-uploads"
+
+// ListIncompleteMultipartUploads lists S3 multipart uploads initiated more than the given number of days ago.
+// With proposeLifecycle, a lifecycle configuration aborting them is proposed for buckets without such a rule.
+func ListIncompleteMultipartUploads(days int, proposeLifecycle bool) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := listBuckets(sess)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := time.Now().AddDate(0, 0, -days)
+	resultList := make([]Result, 0)
+	proposalList := make([]string, 0)
+	for _, b := range buckets {
+		uploads, err := listMultipartUploads(b)
+		if err != nil {
+			return nil, err
+		}
+
+		uploadList := make([]string, 0)
+		bucketSize := int64(0)
+		for _, upload := range uploads {
+			if upload.Initiated.After(threshold) {
+				continue
+			}
+			parts, size, err := countParts(b, upload)
+			if err != nil {
+				return nil, err
+			}
+			bucketSize += size
+			uploadEntry := fmt.Sprint(*upload.Key, ", upload id: ", *upload.UploadId, ", initiated: ", upload.Initiated.Format(time.RFC3339),
+				", parts: ", parts, ", size: ", size, " bytes")
+			uploadList = append(uploadList, uploadEntry)
+		}
+		if len(uploadList) == 0 {
+			continue
+		}
+
+		monthlyCost := float64(bucketSize) / bytesPerGB * s3StorageGBMonthPrices["StandardStorage"]
+		label := fmt.Sprintf("Incomplete multipart uploads in %s, region %s (%d bytes, %s):",
+			*b.name, b.region, bucketSize, formatMonthlyCost(monthlyCost))
+		resultList = append(resultList, Result{label, uploadList})
+
+		if proposeLifecycle {
+			proposal, err := proposeAbortMultipartUploadRule(b, days)
+			if err != nil {
+				return nil, err
+			}
+			if proposal != "" {
+				proposalList = append(proposalList, proposal)
+			}
+		}
+	}
+
+	if proposeLifecycle {
+		resultList = append(resultList, Result{"Proposed lifecycle configurations:", proposalList})
+	}
+	return resultList, nil
+}
+
+// proposeAbortMultipartUploadRule returns the bucket lifecycle configuration, in the format accepted by
+// aws s3api put-bucket-lifecycle-configuration, with the existing rules and a rule aborting
+// multipart uploads after the given number of days, or an empty string if such a rule already exists
+func proposeAbortMultipartUploadRule(b *bucket, days int) (string, error) {
+	rules, err := getLifecycleRules(b)
+	if err != nil {
+		return "", err
+	}
+	if hasAbortIncompleteMultipartUpload(rules) {
+		return "", nil
+	}
+
+	rule := &s3.LifecycleRule{
+		ID:     aws.String(abortMultipartUploadRuleID),
+		Status: aws.String(s3.ExpirationStatusEnabled),
+		Filter: &s3.LifecycleRuleFilter{Prefix: aws.String("")},
+		AbortIncompleteMultipartUpload: &s3.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int64(int64(days)),
+		},
+	}
+	configuration := &s3.BucketLifecycleConfiguration{
+		Rules: append(rules, rule),
+	}
+	proposal, err := marshalWithoutNulls(configuration)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling lifecycle configuration of %v: %w", *b.name, err)
+	}
+	return fmt.Sprint(*b.name, ": ", proposal), nil
+}
+
+// marshalWithoutNulls marshals SDK shapes to JSON, leaving out unset fields
+// which the SDK shapes have no omitempty tags for
+func marshalWithoutNulls(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return "", err
+	}
+	data, err = json.Marshal(removeNulls(decoded))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func removeNulls(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if field == nil {
+				delete(value, key)
+			} else {
+				value[key] = removeNulls(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = removeNulls(item)
+		}
+	}
+	return v
+}
+
+func countParts(b *bucket, upload *s3.MultipartUpload) (int, int64, error) {
+	input := &s3.ListPartsInput{
+		Bucket:   b.name,
+		Key:      upload.Key,
+		UploadId: upload.UploadId,
+	}
+	parts := 0
+	size := int64(0)
+	err := b.s3Svc.ListPartsPages(input, func(page *s3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range page.Parts {
+			parts++
+			size += aws.Int64Value(part.Size)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("error listing parts of %v/%v: %w", *b.name, *upload.Key, err)
+	}
+	return parts, size, nil
+}
+
+func listMultipartUploads(b *bucket) ([]*s3.MultipartUpload, error) {
+	uploads := make([]*s3.MultipartUpload, 0)
+	input := &s3.ListMultipartUploadsInput{
+		Bucket: b.name,
+	}
+	err := b.s3Svc.ListMultipartUploadsPages(input, func(page *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+		uploads = append(uploads, page.Uploads...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing multipart uploads of %v: %w", *b.name, err)
+	}
+	return uploads, nil
+}
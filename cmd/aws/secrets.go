@@ -0,0 +1,56 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// ListUnusedSecrets returns Secrets Manager secrets not accessed for the last given number of days
+func ListUnusedSecrets(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	secretsSvc := secretsmanager.New(sess)
+
+	threshold := time.Now().AddDate(0, 0, -days)
+	secretList := make([]string, 0)
+	err = secretsSvc.ListSecretsPages(&secretsmanager.ListSecretsInput{}, func(page *secretsmanager.ListSecretsOutput, lastPage bool) bool {
+		for _, secret := range page.SecretList {
+			// LastAccessedDate is truncated to the day and is nil for never accessed secrets
+			var secretEntry string
+			switch {
+			case secret.LastAccessedDate != nil && secret.LastAccessedDate.Before(threshold):
+				secretEntry = fmt.Sprint(*secret.Name, ", last accessed: ", secret.LastAccessedDate.Format("2006-01-02"))
+			case secret.LastAccessedDate == nil && secret.CreatedDate != nil && secret.CreatedDate.Before(threshold):
+				secretEntry = fmt.Sprint(*secret.Name, ", created: ", secret.CreatedDate.Format(time.RFC3339), ", never accessed")
+			default:
+				continue
+			}
+			secretList = append(secretList, fmt.Sprint(secretEntry, ", cost: ", formatMonthlyCost(secretMonthlyPrice)))
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing secrets: %w", err)
+	}
+
+	return []Result{{"Secrets not accessed:", secretList}}, nil
+}
@@ -0,0 +1,256 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// securityGroupGraph holds security groups with the resources referring to them
+type securityGroupGraph struct {
+	groups map[string]*ec2.SecurityGroup
+	// attached holds groups used by at least one network interface
+	attached map[string]bool
+	// referrers maps a group to the groups, launch templates, load balancers
+	// and peered VPCs referencing it
+	referrers map[string]map[string]bool
+}
+
+// ListUnusedSecurityGroups returns security groups which are not attached to any network interface
+func ListUnusedSecurityGroups() ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := buildSecurityGroupGraph(sess)
+	if err != nil {
+		return nil, err
+	}
+
+	referencedList := make([]string, 0)
+	orphanedList := make([]string, 0)
+	for _, groupID := range graph.sortedGroupIDs() {
+		group := graph.groups[groupID]
+		if graph.attached[groupID] || *group.GroupName == "default" {
+			continue
+		}
+		sgEntry := fmt.Sprint(*group.GroupName, ", id: ", groupID, ", vpc: ", aws.StringValue(group.VpcId))
+		if referrers := graph.referrers[groupID]; len(referrers) > 0 {
+			sgEntry = sgEntry + ", referenced by: " + strings.Join(sortedKeys(referrers), " ")
+			referencedList = append(referencedList, sgEntry)
+		} else {
+			orphanedList = append(orphanedList, sgEntry)
+		}
+	}
+
+	return []Result{
+		{"Unattached security groups still referenced:", referencedList},
+		{"Orphaned security groups:", orphanedList},
+	}, nil
+}
+
+func buildSecurityGroupGraph(sess *session.Session) (*securityGroupGraph, error) {
+	ec2Svc := ec2.New(sess)
+
+	groups, err := describeSecurityGroups(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+	graph := &securityGroupGraph{
+		groups:    make(map[string]*ec2.SecurityGroup),
+		attached:  make(map[string]bool),
+		referrers: make(map[string]map[string]bool),
+	}
+	for _, group := range groups {
+		graph.groups[*group.GroupId] = group
+	}
+
+	networkInterfaces, err := describeNetworkInterfaces(nil, ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+	for _, eni := range networkInterfaces {
+		for _, group := range eni.Groups {
+			graph.attached[*group.GroupId] = true
+		}
+	}
+
+	for _, group := range groups {
+		permissions := make([]*ec2.IpPermission, 0, len(group.IpPermissions)+len(group.IpPermissionsEgress))
+		permissions = append(permissions, group.IpPermissions...)
+		permissions = append(permissions, group.IpPermissionsEgress...)
+		for _, permission := range permissions {
+			for _, pair := range permission.UserIdGroupPairs {
+				if pair.GroupId != nil && *pair.GroupId != *group.GroupId {
+					graph.addReferrer(*pair.GroupId, *group.GroupId)
+				}
+			}
+		}
+	}
+
+	if err := graph.addLaunchTemplateReferrers(ec2Svc); err != nil {
+		return nil, err
+	}
+	if err := graph.addLoadBalancerReferrers(sess); err != nil {
+		return nil, err
+	}
+	if err := graph.addPeeredVPCReferrers(ec2Svc); err != nil {
+		return nil, err
+	}
+
+	return graph, nil
+}
+
+func (g *securityGroupGraph) addReferrer(groupID, referrer string) {
+	if g.referrers[groupID] == nil {
+		g.referrers[groupID] = make(map[string]bool)
+	}
+	g.referrers[groupID][referrer] = true
+}
+
+// addReferrerByName resolves a group name, as used in EC2-Classic and default VPC, to group IDs
+func (g *securityGroupGraph) addReferrerByName(groupName, referrer string) {
+	for groupID, group := range g.groups {
+		if *group.GroupName == groupName {
+			g.addReferrer(groupID, referrer)
+		}
+	}
+}
+
+func (g *securityGroupGraph) addLaunchTemplateReferrers(ec2Svc *ec2.EC2) error {
+	input := &ec2.DescribeLaunchTemplateVersionsInput{
+		Versions: aws.StringSlice([]string{"$Latest", "$Default"}),
+	}
+	err := ec2Svc.DescribeLaunchTemplateVersionsPages(input, func(page *ec2.DescribeLaunchTemplateVersionsOutput, lastPage bool) bool {
+		for _, version := range page.LaunchTemplateVersions {
+			data := version.LaunchTemplateData
+			if data == nil {
+				continue
+			}
+			referrer := *version.LaunchTemplateId
+			for _, groupID := range data.SecurityGroupIds {
+				g.addReferrer(*groupID, referrer)
+			}
+			for _, groupName := range data.SecurityGroups {
+				g.addReferrerByName(*groupName, referrer)
+			}
+			for _, networkInterface := range data.NetworkInterfaces {
+				for _, groupID := range networkInterface.Groups {
+					g.addReferrer(*groupID, referrer)
+				}
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return fmt.Errorf("error describing launch template versions: %w", err)
+	}
+	return nil
+}
+
+func (g *securityGroupGraph) addLoadBalancerReferrers(sess *session.Session) error {
+	elbList, err := describeAllELBs(elbv2.New(sess))
+	if err != nil {
+		return err
+	}
+	for _, lb := range elbList {
+		for _, groupID := range lb.SecurityGroups {
+			g.addReferrer(*groupID, *lb.LoadBalancerName)
+		}
+	}
+
+	classicELBList, err := describeAllClassicLBs(elb.New(sess))
+	if err != nil {
+		return err
+	}
+	for _, lb := range classicELBList {
+		for _, groupID := range lb.SecurityGroups {
+			g.addReferrer(*groupID, *lb.LoadBalancerName)
+		}
+	}
+	return nil
+}
+
+// addPeeredVPCReferrers adds references from security group rules in peered VPCs,
+// which are not visible when describing groups of this account and region
+func (g *securityGroupGraph) addPeeredVPCReferrers(ec2Svc *ec2.EC2) error {
+	candidates := make([]*string, 0)
+	for groupID, group := range g.groups {
+		if !g.attached[groupID] && group.VpcId != nil {
+			candidates = append(candidates, aws.String(groupID))
+		}
+	}
+
+	const batchSize = 100
+	for start := 0; start < len(candidates); start += batchSize {
+		end := start + batchSize
+		if end > len(candidates) {
+			end = len(candidates)
+		}
+		input := &ec2.DescribeSecurityGroupReferencesInput{
+			GroupId: candidates[start:end],
+		}
+		output, err := ec2Svc.DescribeSecurityGroupReferences(input)
+		if err != nil {
+			return fmt.Errorf("error describing security group references: %w", err)
+		}
+		for _, reference := range output.SecurityGroupReferenceSet {
+			g.addReferrer(*reference.GroupId, *reference.ReferencingVpcId)
+		}
+	}
+	return nil
+}
+
+func (g *securityGroupGraph) sortedGroupIDs() []string {
+	groupIDs := make([]string, 0, len(g.groups))
+	for groupID := range g.groups {
+		groupIDs = append(groupIDs, groupID)
+	}
+	sort.Strings(groupIDs)
+	return groupIDs
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func describeSecurityGroups(ec2Svc *ec2.EC2) ([]*ec2.SecurityGroup, error) {
+	groups := make([]*ec2.SecurityGroup, 0)
+	input := &ec2.DescribeSecurityGroupsInput{}
+	err := ec2Svc.DescribeSecurityGroupsPages(input, func(page *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+		groups = append(groups, page.SecurityGroups...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing security groups: %w", err)
+	}
+
+	return groups, nil
+}
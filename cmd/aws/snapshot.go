@@ -0,0 +1,43 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/redshift"
+)
+
+// ListOrphanedSnapshots lists manual Redshift and ElastiCache snapshots of clusters which no longer exist
+func ListOrphanedSnapshots() ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	redshiftList, err := listOrphanedRedshiftSnapshots(redshift.New(sess))
+	if err != nil {
+		return nil, err
+	}
+	elastiCacheList, err := listOrphanedCacheSnapshots(elasticache.New(sess))
+	if err != nil {
+		return nil, err
+	}
+
+	return []Result{
+		{"Redshift snapshots of deleted clusters:", redshiftList},
+		{"ElastiCache snapshots of deleted clusters:", elastiCacheList},
+	}, nil
+}
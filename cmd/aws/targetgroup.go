@@ -0,0 +1,152 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// ListUnusedTargetGroups returns ELBv2 target groups not associated with any load balancer,
+// with no registered targets or with only unhealthy or terminated targets
+func ListUnusedTargetGroups() ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	elbSvc := elbv2.New(sess)
+	ec2Svc := ec2.New(sess)
+
+	targetGroups, err := describeAllTargetGroups(elbSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	orphanedList := make([]string, 0)
+	emptyList := make([]string, 0)
+	unhealthyList := make([]string, 0)
+	for _, targetGroup := range targetGroups {
+		if len(targetGroup.LoadBalancerArns) == 0 {
+			orphanedList = append(orphanedList, formatTargetGroup(targetGroup))
+			continue
+		}
+
+		input := &elbv2.DescribeTargetHealthInput{
+			TargetGroupArn: targetGroup.TargetGroupArn,
+		}
+		output, err := elbSvc.DescribeTargetHealth(input)
+		if err != nil {
+			return nil, fmt.Errorf("error describing Target Groups Health %v: %w", *targetGroup.TargetGroupArn, err)
+		}
+		if len(output.TargetHealthDescriptions) == 0 {
+			emptyList = append(emptyList, formatTargetGroup(targetGroup))
+			continue
+		}
+		if !allTargetsUnhealthy(output.TargetHealthDescriptions) {
+			continue
+		}
+		tgEntry := formatTargetGroup(targetGroup)
+		if aws.StringValue(targetGroup.TargetType) == elbv2.TargetTypeEnumInstance {
+			terminated, err := countTerminatedTargets(output.TargetHealthDescriptions, ec2Svc)
+			if err != nil {
+				return nil, err
+			}
+			tgEntry = fmt.Sprint(tgEntry, ", terminated targets: ", terminated, "/", len(output.TargetHealthDescriptions))
+		}
+		unhealthyList = append(unhealthyList, tgEntry)
+	}
+
+	return []Result{
+		{"Target groups not associated with a load balancer:", orphanedList},
+		{"Target groups with no registered targets:", emptyList},
+		{"Target groups with only unhealthy or terminated targets:", unhealthyList},
+	}, nil
+}
+
+func allTargetsUnhealthy(targets []*elbv2.TargetHealthDescription) bool {
+	for _, target := range targets {
+		switch aws.StringValue(target.TargetHealth.State) {
+		case elbv2.TargetHealthStateEnumUnhealthy, elbv2.TargetHealthStateEnumUnused, elbv2.TargetHealthStateEnumUnavailable:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// countTerminatedTargets returns the number of instance targets which are terminated or no longer exist
+func countTerminatedTargets(targets []*elbv2.TargetHealthDescription, ec2Svc *ec2.EC2) (int, error) {
+	instanceIDs := make([]*string, 0, len(targets))
+	for _, target := range targets {
+		instanceIDs = append(instanceIDs, target.Target.Id)
+	}
+
+	// Terminated instances disappear from DescribeInstances after a while,
+	// so the instance-id filter is used instead of the InstanceIds parameter.
+	// A filter accepts at most maxFilterValues values
+	instances := make([]*ec2.Instance, 0, len(instanceIDs))
+	for start := 0; start < len(instanceIDs); start += maxFilterValues {
+		end := start + maxFilterValues
+		if end > len(instanceIDs) {
+			end = len(instanceIDs)
+		}
+		filter := &ec2.Filter{
+			Name:   aws.String("instance-id"),
+			Values: instanceIDs[start:end],
+		}
+		batch, err := describeEC2Instances(nil, []*ec2.Filter{filter}, ec2Svc)
+		if err != nil {
+			return 0, fmt.Errorf("error describing EC2 instances: %w", err)
+		}
+		instances = append(instances, batch...)
+	}
+
+	running := 0
+	for _, instance := range instances {
+		if *instance.State.Name != ec2.InstanceStateNameTerminated {
+			running++
+		}
+	}
+	return len(targets) - running, nil
+}
+
+func formatTargetGroup(targetGroup *elbv2.TargetGroup) string {
+	tgEntry := fmt.Sprint(*targetGroup.TargetGroupName, ", type: ", aws.StringValue(targetGroup.TargetType))
+	if targetGroup.Protocol != nil {
+		tgEntry = tgEntry + ", protocol: " + *targetGroup.Protocol
+	}
+	if targetGroup.Port != nil {
+		tgEntry = fmt.Sprint(tgEntry, ", port: ", *targetGroup.Port)
+	}
+	return tgEntry
+}
+
+func describeAllTargetGroups(elbV2Svc *elbv2.ELBV2) ([]*elbv2.TargetGroup, error) {
+	targetGroups := make([]*elbv2.TargetGroup, 0)
+	input := &elbv2.DescribeTargetGroupsInput{}
+	err := elbV2Svc.DescribeTargetGroupsPages(input, func(page *elbv2.DescribeTargetGroupsOutput, lastPage bool) bool {
+		targetGroups = append(targetGroups, page.TargetGroups...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing Target Groups: %w", err)
+	}
+
+	return targetGroups, nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// ListUnusedTransitGatewayAttachments returns Transit Gateway attachments with no traffic
+// for the last given number of days
+func ListUnusedTransitGatewayAttachments(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ec2Svc := ec2.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	attachments, err := describeAvailableTransitGatewayAttachments(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
+	attachmentList := make([]string, 0)
+	for _, attachment := range attachments {
+		dimensions := append(newDimension("TransitGateway", attachment.TransitGatewayId),
+			newDimension("TransitGatewayAttachment", attachment.TransitGatewayAttachmentId)...)
+		used := false
+		for _, metricName := range []string{"BytesIn", "BytesOut"} {
+			bytes, err := sumMetric(cwSvc, "AWS/TransitGateway", metricName, dimensions, days)
+			if err != nil {
+				return nil, fmt.Errorf("error getting Transit Gateway attachment %v metrics: %w", *attachment.TransitGatewayAttachmentId, err)
+			}
+			if bytes > 0 {
+				used = true
+				break
+			}
+		}
+		if used {
+			continue
+		}
+
+		attachmentEntry := fmt.Sprint(*attachment.TransitGatewayAttachmentId, ", transit gateway: ", *attachment.TransitGatewayId,
+			", type: ", aws.StringValue(attachment.ResourceType), ", resource: ", aws.StringValue(attachment.ResourceId),
+			", cost: ", formatHourlyCost(transitGatewayAttachmentHourlyPrice))
+		attachmentList = append(attachmentList, attachmentEntry)
+	}
+
+	return []Result{{"Transit Gateway attachments with no traffic:", attachmentList}}, nil
+}
+
+func describeAvailableTransitGatewayAttachments(ec2Svc *ec2.EC2) ([]*ec2.TransitGatewayAttachment, error) {
+	attachments := make([]*ec2.TransitGatewayAttachment, 0)
+	input := &ec2.DescribeTransitGatewayAttachmentsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("state"),
+				Values: []*string{aws.String(ec2.TransitGatewayAttachmentStateAvailable)},
+			},
+		},
+	}
+	err := ec2Svc.DescribeTransitGatewayAttachmentsPages(input, func(page *ec2.DescribeTransitGatewayAttachmentsOutput, lastPage bool) bool {
+		attachments = append(attachments, page.TransitGatewayAttachments...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing Transit Gateway attachments: %w", err)
+	}
+	return attachments, nil
+}
@@ -17,8 +17,13 @@ package aws
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 )
 
 type Result struct {
@@ -35,3 +40,38 @@ func newSession() (*session.Session, error) {
 	}
 	return sess, nil
 }
+
+func getAccountID(sess *session.Session) (string, error) {
+	output, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("error getting caller identity: %w", err)
+	}
+	return *output.Account, nil
+}
+
+// partitionForRegion returns the partition (aws, aws-cn, aws-us-gov) the region belongs to
+func partitionForRegion(region string) string {
+	if partition, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		return partition.ID()
+	}
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return endpoints.AwsCnPartitionID
+	case strings.HasPrefix(region, "us-gov-"):
+		return endpoints.AwsUsGovPartitionID
+	default:
+		return endpoints.AwsPartitionID
+	}
+}
+
+func sessionRegion(sess *session.Session) string {
+	return aws.StringValue(sess.Config.Region)
+}
+
+// isErrorCode reports whether err is an AWS API error with the given code
+func isErrorCode(err error, code string) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == code
+	}
+	return false
+}
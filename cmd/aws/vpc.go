@@ -0,0 +1,237 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// vpcResources holds the resources which are deleted together with a VPC
+type vpcResources struct {
+	routeTables      []string
+	internetGateways []string
+	networkACLs      []string
+	endpoints        []string
+	subnets          []string
+}
+
+// ReportEmptyVPCs lists VPCs and subnets without network interfaces in all enabled regions,
+// including default VPCs, with the resources attached to them
+func ReportEmptyVPCs() ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+
+	regions, err := describeRegions(ec2.New(sess))
+	if err != nil {
+		return nil, err
+	}
+
+	defaultVPCList := make([]string, 0)
+	vpcList := make([]string, 0)
+	subnetList := make([]string, 0)
+	for _, region := range regions {
+		ec2Svc := ec2.New(sess.Copy(aws.NewConfig().WithRegion(region)))
+
+		vpcs, err := describeVPCs(ec2Svc)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", region, err)
+		}
+		if len(vpcs) == 0 {
+			continue
+		}
+		subnets, err := describeSubnets(ec2Svc)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", region, err)
+		}
+		networkInterfaces, err := describeNetworkInterfaces(nil, ec2Svc)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", region, err)
+		}
+		resources, err := getVPCResources(ec2Svc, subnets)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", region, err)
+		}
+
+		usedVPCs := make(map[string]bool)
+		usedSubnets := make(map[string]bool)
+		for _, eni := range networkInterfaces {
+			usedVPCs[aws.StringValue(eni.VpcId)] = true
+			usedSubnets[aws.StringValue(eni.SubnetId)] = true
+		}
+
+		for _, vpc := range vpcs {
+			if usedVPCs[*vpc.VpcId] {
+				continue
+			}
+			vpcEntry := fmt.Sprint(formatVPC(vpc, region), ", ", resources[*vpc.VpcId].format())
+			if aws.BoolValue(vpc.IsDefault) {
+				defaultVPCList = append(defaultVPCList, vpcEntry)
+			} else {
+				vpcList = append(vpcList, vpcEntry)
+			}
+		}
+
+		// subnets of empty VPCs are already reported with their VPC
+		for _, subnet := range subnets {
+			if usedSubnets[*subnet.SubnetId] || !usedVPCs[aws.StringValue(subnet.VpcId)] {
+				continue
+			}
+			subnetEntry := fmt.Sprint(*subnet.SubnetId, ", region: ", region, ", vpc: ", aws.StringValue(subnet.VpcId),
+				", AZ: ", aws.StringValue(subnet.AvailabilityZone), ", CIDR: ", aws.StringValue(subnet.CidrBlock),
+				", free IPs: ", aws.Int64Value(subnet.AvailableIpAddressCount))
+			if name := getNameTag(subnet.Tags); name != nil {
+				subnetEntry = subnetEntry + ", name: " + *name
+			}
+			subnetList = append(subnetList, subnetEntry)
+		}
+	}
+
+	return []Result{
+		{"Empty default VPCs:", defaultVPCList},
+		{"Empty VPCs:", vpcList},
+		{"Empty subnets in VPCs with workloads:", subnetList},
+	}, nil
+}
+
+func formatVPC(vpc *ec2.Vpc, region string) string {
+	cidrBlocks := make([]string, 0, len(vpc.CidrBlockAssociationSet))
+	for _, association := range vpc.CidrBlockAssociationSet {
+		cidrBlocks = append(cidrBlocks, aws.StringValue(association.CidrBlock))
+	}
+	vpcEntry := fmt.Sprint(*vpc.VpcId, ", region: ", region, ", CIDR: [", strings.Join(cidrBlocks, " "), "]")
+	if name := getNameTag(vpc.Tags); name != nil {
+		vpcEntry = vpcEntry + ", name: " + *name
+	}
+	return vpcEntry
+}
+
+func (r *vpcResources) format() string {
+	if r == nil {
+		r = &vpcResources{}
+	}
+	return fmt.Sprint("subnets: [", strings.Join(r.subnets, " "), "], route tables: [", strings.Join(r.routeTables, " "),
+		"], internet gateways: [", strings.Join(r.internetGateways, " "), "], network ACLs: [", strings.Join(r.networkACLs, " "),
+		"], endpoints: [", strings.Join(r.endpoints, " "), "]")
+}
+
+// getVPCResources groups subnets, route tables, internet gateways, network ACLs and VPC endpoints by VPC ID
+func getVPCResources(ec2Svc *ec2.EC2, subnets []*ec2.Subnet) (map[string]*vpcResources, error) {
+	resources := make(map[string]*vpcResources)
+	get := func(vpcID *string) *vpcResources {
+		r, ok := resources[aws.StringValue(vpcID)]
+		if !ok {
+			r = &vpcResources{}
+			resources[aws.StringValue(vpcID)] = r
+		}
+		return r
+	}
+
+	for _, subnet := range subnets {
+		r := get(subnet.VpcId)
+		r.subnets = append(r.subnets, *subnet.SubnetId)
+	}
+
+	err := ec2Svc.DescribeRouteTablesPages(&ec2.DescribeRouteTablesInput{}, func(page *ec2.DescribeRouteTablesOutput, lastPage bool) bool {
+		for _, routeTable := range page.RouteTables {
+			r := get(routeTable.VpcId)
+			r.routeTables = append(r.routeTables, *routeTable.RouteTableId)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing route tables: %w", err)
+	}
+
+	err = ec2Svc.DescribeInternetGatewaysPages(&ec2.DescribeInternetGatewaysInput{}, func(page *ec2.DescribeInternetGatewaysOutput, lastPage bool) bool {
+		for _, gateway := range page.InternetGateways {
+			for _, attachment := range gateway.Attachments {
+				r := get(attachment.VpcId)
+				r.internetGateways = append(r.internetGateways, *gateway.InternetGatewayId)
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing internet gateways: %w", err)
+	}
+
+	err = ec2Svc.DescribeNetworkAclsPages(&ec2.DescribeNetworkAclsInput{}, func(page *ec2.DescribeNetworkAclsOutput, lastPage bool) bool {
+		for _, acl := range page.NetworkAcls {
+			r := get(acl.VpcId)
+			r.networkACLs = append(r.networkACLs, *acl.NetworkAclId)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing network ACLs: %w", err)
+	}
+
+	err = ec2Svc.DescribeVpcEndpointsPages(&ec2.DescribeVpcEndpointsInput{}, func(page *ec2.DescribeVpcEndpointsOutput, lastPage bool) bool {
+		for _, endpoint := range page.VpcEndpoints {
+			r := get(endpoint.VpcId)
+			r.endpoints = append(r.endpoints, *endpoint.VpcEndpointId)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing VPC endpoints: %w", err)
+	}
+
+	return resources, nil
+}
+
+// describeRegions returns the regions enabled for the account
+func describeRegions(ec2Svc *ec2.EC2) ([]string, error) {
+	output, err := ec2Svc.DescribeRegions(&ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error describing regions: %w", err)
+	}
+	regions := make([]string, 0, len(output.Regions))
+	for _, region := range output.Regions {
+		regions = append(regions, *region.RegionName)
+	}
+	return regions, nil
+}
+
+func describeVPCs(ec2Svc *ec2.EC2) ([]*ec2.Vpc, error) {
+	vpcs := make([]*ec2.Vpc, 0)
+	err := ec2Svc.DescribeVpcsPages(&ec2.DescribeVpcsInput{}, func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+		vpcs = append(vpcs, page.Vpcs...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing VPCs: %w", err)
+	}
+	return vpcs, nil
+}
+
+func describeSubnets(ec2Svc *ec2.EC2) ([]*ec2.Subnet, error) {
+	subnets := make([]*ec2.Subnet, 0)
+	err := ec2Svc.DescribeSubnetsPages(&ec2.DescribeSubnetsInput{}, func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+		subnets = append(subnets, page.Subnets...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing subnets: %w", err)
+	}
+	return subnets, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// ListUnusedVPCEndpoints returns interface VPC endpoints with no processed bytes for the last given number of days
+func ListUnusedVPCEndpoints(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ec2Svc := ec2.New(sess)
+	cwSvc := cloudwatch.New(sess)
+
+	endpoints, err := describeInterfaceVPCEndpoints(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointList := make([]string, 0)
+	for _, endpoint := range endpoints {
+		// PrivateLink metrics are only published with the full set of endpoint dimensions
+		dimensions := []*cloudwatch.Dimension{
+			{Name: aws.String("Endpoint Type"), Value: endpoint.VpcEndpointType},
+			{Name: aws.String("Service Name"), Value: endpoint.ServiceName},
+			{Name: aws.String("VPC Endpoint Id"), Value: endpoint.VpcEndpointId},
+			{Name: aws.String("VPC Id"), Value: endpoint.VpcId},
+		}
+		bytesProcessed, err := sumMetric(cwSvc, "AWS/PrivateLinkEndpoints", "BytesProcessed", dimensions, days)
+		if err != nil {
+			return nil, fmt.Errorf("error getting VPC endpoint %v metrics: %w", *endpoint.VpcEndpointId, err)
+		}
+		if bytesProcessed > 0 {
+			continue
+		}
+
+		// Interface endpoints are billed per AZ, i.e. per network interface
+		hourlyCost := vpcEndpointHourlyPrice * float64(len(endpoint.NetworkInterfaceIds))
+		endpointEntry := fmt.Sprint(*endpoint.VpcEndpointId, ", service: ", aws.StringValue(endpoint.ServiceName),
+			", vpc: ", aws.StringValue(endpoint.VpcId), ", AZs: ", len(endpoint.NetworkInterfaceIds),
+			", cost: ", formatHourlyCost(hourlyCost))
+		endpointList = append(endpointList, endpointEntry)
+	}
+
+	return []Result{{"Interface VPC endpoints with no traffic:", endpointList}}, nil
+}
+
+func describeInterfaceVPCEndpoints(ec2Svc *ec2.EC2) ([]*ec2.VpcEndpoint, error) {
+	endpoints := make([]*ec2.VpcEndpoint, 0)
+	input := &ec2.DescribeVpcEndpointsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("vpc-endpoint-type"),
+				Values: []*string{aws.String("Interface")},
+			},
+			{
+				Name:   aws.String("vpc-endpoint-state"),
+				Values: []*string{aws.String("available")},
+			},
+		},
+	}
+	err := ec2Svc.DescribeVpcEndpointsPages(input, func(page *ec2.DescribeVpcEndpointsOutput, lastPage bool) bool {
+		endpoints = append(endpoints, page.VpcEndpoints...)
+		return !lastPage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing VPC endpoints: %w", err)
+	}
+	return endpoints, nil
+}
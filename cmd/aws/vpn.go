@@ -0,0 +1,133 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// ListUnusedVPNs returns VPN connections with all tunnels down for the last given number of days
+// and virtual private gateways not attached to any VPC
+func ListUnusedVPNs(days int) ([]Result, error) {
+	sess, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	ec2Svc := ec2.New(sess)
+
+	connections, err := describeAvailableVPNConnections(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := time.Now().AddDate(0, 0, -days)
+	vpnList := make([]string, 0)
+	for _, connection := range connections {
+		if downSince, down := tunnelsDownSince(connection.VgwTelemetry); down && downSince.Before(threshold) {
+			vpnEntry := fmt.Sprint(*connection.VpnConnectionId, ", customer gateway: ", aws.StringValue(connection.CustomerGatewayId),
+				", down since: ", downSince.Format(time.RFC3339), ", cost: ", formatHourlyCost(vpnConnectionHourlyPrice))
+			if connection.TransitGatewayId != nil {
+				vpnEntry = vpnEntry + ", transit gateway: " + *connection.TransitGatewayId
+			} else {
+				vpnEntry = vpnEntry + ", vpn gateway: " + aws.StringValue(connection.VpnGatewayId)
+			}
+			vpnList = append(vpnList, vpnEntry)
+		}
+	}
+
+	gateways, err := describeAvailableVPNGateways(ec2Svc)
+	if err != nil {
+		return nil, err
+	}
+
+	gatewayList := make([]string, 0)
+	for _, gateway := range gateways {
+		if !isVPNGatewayAttached(gateway) {
+			gatewayEntry := fmt.Sprint(*gateway.VpnGatewayId, ", ASN: ", aws.Int64Value(gateway.AmazonSideAsn))
+			if name := getNameTag(gateway.Tags); name != nil {
+				gatewayEntry = gatewayEntry + ", name: " + *name
+			}
+			gatewayList = append(gatewayList, gatewayEntry)
+		}
+	}
+
+	return []Result{
+		{"VPN connections down:", vpnList},
+		{"Virtual private gateways not attached to a VPC:", gatewayList},
+	}, nil
+}
+
+// tunnelsDownSince returns the time of the latest tunnel status change if all tunnels are down
+func tunnelsDownSince(telemetry []*ec2.VgwTelemetry) (time.Time, bool) {
+	var downSince time.Time
+	if len(telemetry) == 0 {
+		return downSince, false
+	}
+	for _, tunnel := range telemetry {
+		if aws.StringValue(tunnel.Status) != ec2.TelemetryStatusDown {
+			return downSince, false
+		}
+		if tunnel.LastStatusChange != nil && tunnel.LastStatusChange.After(downSince) {
+			downSince = *tunnel.LastStatusChange
+		}
+	}
+	return downSince, true
+}
+
+func isVPNGatewayAttached(gateway *ec2.VpnGateway) bool {
+	for _, attachment := range gateway.VpcAttachments {
+		if aws.StringValue(attachment.State) == ec2.AttachmentStatusAttached {
+			return true
+		}
+	}
+	return false
+}
+
+func describeAvailableVPNConnections(ec2Svc *ec2.EC2) ([]*ec2.VpnConnection, error) {
+	input := &ec2.DescribeVpnConnectionsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("state"),
+				Values: []*string{aws.String(ec2.VpnStateAvailable)},
+			},
+		},
+	}
+	output, err := ec2Svc.DescribeVpnConnections(input)
+	if err != nil {
+		return nil, fmt.Errorf("error describing VPN connections: %w", err)
+	}
+	return output.VpnConnections, nil
+}
+
+func describeAvailableVPNGateways(ec2Svc *ec2.EC2) ([]*ec2.VpnGateway, error) {
+	input := &ec2.DescribeVpnGatewaysInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("state"),
+				Values: []*string{aws.String(ec2.VpnStateAvailable)},
+			},
+		},
+	}
+	output, err := ec2Svc.DescribeVpnGateways(input)
+	if err != nil {
+		return nil, fmt.Errorf("error describing VPN gateways: %w", err)
+	}
+	return output.VpnGateways, nil
+}
@@ -46,7 +46,7 @@ func ListUnusedLBs() ([]aws.Result, error) {
 		}
 	}
 
-	return []aws.Result{{"Unattached LBs:", unattachedLBList}}, nil
+	return []aws.Result{{Label: "Unattached LBs:", Resources: unattachedLBList}}, nil
 }
 
 func isBackendAddressPoolsEmpty(pools *[]network.BackendAddressPool) bool {
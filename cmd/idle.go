@@ -24,13 +24,15 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var idleDays int
+
 // idleCmd represents the idle command
 var idleCmd = &cobra.Command{
 	Use:       "idle",
 	Short:     "Find idle cloud resources",
-	Long:      `Scan your EBS and find idle ones.`,
+	Long:      `Scan your ELBs, EBSs, NAT gateways, DynamoDB tables, ECS and EKS clusters, ElastiCache clusters, OpenSearch domains, Redshift clusters and find idle ones.`,
 	Args:      cobra.OnlyValidArgs,
-	ValidArgs: []string{"ebs"},
+	ValidArgs: []string{"elb", "elbv2", "ebs", "nat", "dynamodb", "ecs", "eks", "elasticache", "opensearch", "redshift"},
 	Run: func(cmd *cobra.Command, args []string) {
 		ticker := time.NewTicker(200 * time.Millisecond)
 		tickerDone := make(chan bool)
@@ -56,8 +58,26 @@ var idleCmd = &cobra.Command{
 
 func findIdleResources(resourceType string) ([]aws.Result, error) {
 	switch resourceType {
+	case "elb":
+		return aws.ListIdleClassicLBs(idleDays)
+	case "elbv2":
+		return aws.ListIdleELBs(idleDays)
 	case "ebs":
-		return aws.ListIdleEBSs()
+		return aws.ListIdleEBSs(idleDays)
+	case "nat":
+		return aws.ListIdleNATGateways(idleDays)
+	case "dynamodb":
+		return aws.ListIdleDynamoDBTables(idleDays)
+	case "ecs":
+		return aws.ListIdleECSResources(idleDays)
+	case "eks":
+		return aws.ListIdleEKSResources(idleDays)
+	case "elasticache":
+		return aws.ListIdleCacheClusters(idleDays)
+	case "opensearch":
+		return aws.ListIdleOpenSearchDomains(idleDays)
+	case "redshift":
+		return aws.ListIdleRedshiftClusters(idleDays)
 	default:
 		return nil, fmt.Errorf("Unknown resource type '%s", resourceType)
 	}
@@ -75,4 +95,5 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// idleCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	idleCmd.Flags().IntVarP(&idleDays, "days", "d", 7, "lookback window in days")
 }
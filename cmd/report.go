@@ -0,0 +1,70 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aint/CloudElephant/cmd/aws"
+
+	"github.com/spf13/cobra"
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:       "report",
+	Short:     "Report cloud resources usage and cost",
+	Long:      `Report your public IPv4 addresses with their cost, and empty VPCs and subnets in all regions.`,
+	Args:      cobra.OnlyValidArgs,
+	ValidArgs: []string{"ipv4", "vpc"},
+	Run: func(cmd *cobra.Command, args []string) {
+		ticker := time.NewTicker(200 * time.Millisecond)
+		tickerDone := make(chan bool)
+
+		go printProgressBar(ticker, tickerDone)
+
+		resultList, err := reportResources(args[0])
+		if err != nil {
+			fmt.Println("ERROR: ", err)
+			return
+		}
+
+		tickerDone <- true
+
+		for _, result := range resultList {
+			fmt.Println("\n", result.Label)
+			for _, res := range result.Resources {
+				fmt.Println(" - ", res)
+			}
+		}
+	},
+}
+
+func reportResources(resourceType string) ([]aws.Result, error) {
+	switch resourceType {
+	case "ipv4":
+		return aws.ReportPublicIPv4Addresses()
+	case "vpc":
+		return aws.ReportEmptyVPCs()
+	default:
+		return nil, fmt.Errorf("Unknown resource type '%s", resourceType)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+}
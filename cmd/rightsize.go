@@ -0,0 +1,72 @@
+/*
+Copyright © 2020 - 2021 Oleksandr Tyshkovets <olexandr.tyshkovets@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aint/CloudElephant/cmd/aws"
+
+	"github.com/spf13/cobra"
+)
+
+var rightsizeDays int
+
+// rightsizeCmd represents the rightsize command
+var rightsizeCmd = &cobra.Command{
+	Use:       "rightsize",
+	Short:     "Find oversized cloud resources",
+	Long:      `Scan your EC2 instances and recommend smaller or Graviton instance types.`,
+	Args:      cobra.OnlyValidArgs,
+	ValidArgs: []string{"ec2"},
+	Run: func(cmd *cobra.Command, args []string) {
+		ticker := time.NewTicker(200 * time.Millisecond)
+		tickerDone := make(chan bool)
+
+		go printProgressBar(ticker, tickerDone)
+
+		resultList, err := rightsizeResources(args[0])
+		if err != nil {
+			fmt.Println("ERROR: ", err)
+			return
+		}
+
+		tickerDone <- true
+
+		for _, result := range resultList {
+			fmt.Println("\n", result.Label)
+			for _, res := range result.Resources {
+				fmt.Println(" - ", res)
+			}
+		}
+	},
+}
+
+func rightsizeResources(resourceType string) ([]aws.Result, error) {
+	switch resourceType {
+	case "ec2":
+		return aws.RightsizeEC2s(rightsizeDays)
+	default:
+		return nil, fmt.Errorf("Unknown resource type '%s", resourceType)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(rightsizeCmd)
+
+	rightsizeCmd.Flags().IntVarP(&rightsizeDays, "days", "d", 14, "lookback window in days")
+}
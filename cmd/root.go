@@ -39,8 +39,23 @@ Supports:
  - AWS EIP (Elastic IP Addresses)
  - AWS EBS (Elastic Block Store)
  - AWS AMI (Machine Images)
+ - AWS Public IPv4
+ - AWS NAT Gateway
+ - AWS ENI (Elastic Network Interfaces)
+ - AWS Security Groups
+ - AWS Lambda
+ - AWS DynamoDB
+ - AWS S3 (Simple Storage Service)
+ - AWS CloudWatch Logs
+ - AWS ECR (Elastic Container Registry)
+ - AWS ECS and EKS
+ - AWS ElastiCache, OpenSearch and Redshift
+ - AWS VPC Endpoints, VPN and Transit Gateway
+ - AWS VPC
+ - AWS IAM
+ - AWS KMS and Secrets Manager
  - AWS RDS (Relational Database Service) [planned]
- - AWS EC2 (Elastic Compute Cloud) [planned]
+ - AWS EC2 (Elastic Compute Cloud)
  - Azure Managed Disk [planned]
  - Azure Load Balancer [planned]
 `,
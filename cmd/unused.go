@@ -25,13 +25,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var unusedDays int
+var proposeLifecycle bool
+
 // unusedCmd represents the unused command
 var unusedCmd = &cobra.Command{
 	Use:       "unused",
 	Short:     "Find unused cloud resources",
-	Long:      `Scan your ELBs, EBSs, EIPs, AMIs, Azure LBs and find unused ones.`,
+	Long:      `Scan your ELBs, EBSs, EIPs, AMIs, NAT gateways, ENIs, security groups, target groups, stopped EC2 instances, Lambda functions, S3 buckets, S3 multipart uploads, log groups, ECR images, VPC endpoints, VPN connections and gateways, Transit Gateway attachments, IAM users, roles, access keys and policies, KMS keys, secrets, Redshift and ElastiCache snapshots, Azure LBs and find unused ones.`,
 	Args:      cobra.OnlyValidArgs,
-	ValidArgs: []string{"elb", "elbv2", "ebs", "eip", "ami", "azlb"},
+	ValidArgs: []string{"elb", "elbv2", "ebs", "eip", "ami", "nat", "eni", "sg", "tg", "ec2", "lambda", "s3", "s3-mpu", "logs", "ecr", "vpce", "vpn", "tgw", "iam", "kms", "secrets", "snapshot", "azlb"},
 	Run: func(cmd *cobra.Command, args []string) {
 		ticker := time.NewTicker(200 * time.Millisecond)
 		tickerDone := make(chan bool)
@@ -67,6 +70,40 @@ func findUnusedResources(resourceType string) ([]aws.Result, error) {
 		return aws.ListUnusedEBSs()
 	case "ami":
 		return aws.ListUnusedAMIs()
+	case "nat":
+		return aws.ListUnusedNATGateways()
+	case "eni":
+		return aws.ListUnattachedENIs()
+	case "sg":
+		return aws.ListUnusedSecurityGroups()
+	case "tg":
+		return aws.ListUnusedTargetGroups()
+	case "ec2":
+		return aws.ListStoppedEC2s(unusedDays)
+	case "lambda":
+		return aws.ListUnusedLambdas(unusedDays)
+	case "s3":
+		return aws.ListUnusedS3Buckets(unusedDays)
+	case "s3-mpu":
+		return aws.ListIncompleteMultipartUploads(unusedDays, proposeLifecycle)
+	case "logs":
+		return aws.ListUnusedLogGroups(unusedDays)
+	case "ecr":
+		return aws.ListUnusedECRImages(unusedDays)
+	case "vpce":
+		return aws.ListUnusedVPCEndpoints(unusedDays)
+	case "vpn":
+		return aws.ListUnusedVPNs(unusedDays)
+	case "tgw":
+		return aws.ListUnusedTransitGatewayAttachments(unusedDays)
+	case "iam":
+		return aws.ListUnusedIAMResources(unusedDays)
+	case "kms":
+		return aws.ListUnusedKMSKeys(unusedDays)
+	case "secrets":
+		return aws.ListUnusedSecrets(unusedDays)
+	case "snapshot":
+		return aws.ListOrphanedSnapshots()
 	case "azlb":
 		return azure.ListUnusedLBs()
 	default:
@@ -97,4 +134,6 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// unusedCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	unusedCmd.Flags().IntVarP(&unusedDays, "days", "d", 30, "number of days a resource has to be unused for")
+	unusedCmd.Flags().BoolVar(&proposeLifecycle, "propose-lifecycle", false, "propose S3 lifecycle rules aborting incomplete multipart uploads")
 }